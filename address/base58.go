@@ -26,12 +26,17 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"math/big"
 )
 
 const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
 
-var decodeMap [255]byte
+var decodeMap [256]byte
+
+//pow58 is 58^i.
+var pow58 = [...]uint64{1, 58, 58 * 58, 58 * 58 * 58, 58 * 58 * 58 * 58, 58 * 58 * 58 * 58 * 58}
+
+//radix58 is a radix of limbs when encoding, which is 58^5 < 2^32.
+const radix58 = 58 * 58 * 58 * 58 * 58
 
 func init() {
 	for i := 0; i < len(decodeMap); i++ {
@@ -49,14 +54,20 @@ func Encode58(encoded []byte) string {
 	hash := sha256.Sum256(encoded)
 	hash = sha256.Sum256(hash[:])
 	copy(out[len(encoded):], hash[0:4])
-	bigcksum := new(big.Int).SetBytes(out)
-	cksum := encodeBig(bigcksum)
-	buffer := make([]byte, 0, len(cksum))
-
-	for i := 0; i < len(encoded) && encoded[i] == 0; i++ {
-		buffer = append(buffer, '1')
+	zeros := 0
+	for zeros < len(encoded) && encoded[zeros] == 0 {
+		zeros++
+	}
+	i := 0
+	for i < len(out) && out[i] == 0 {
+		i++
+	}
+	cksum := encodeDigits(out[i:])
+	buffer := make([]byte, zeros+len(cksum))
+	for j := 0; j < zeros; j++ {
+		buffer[j] = '1'
 	}
-	buffer = append(buffer, cksum...)
+	copy(buffer[zeros:], cksum)
 	return string(buffer)
 }
 
@@ -65,29 +76,27 @@ func Decode58(value string) ([]byte, error) {
 	if len(value) < 5 {
 		return nil, errors.New("invalid input")
 	}
-	var pk big.Int
-	if err := decodeToBig([]byte(value), &pk); err != nil {
+	ecksum, err := decodeDigits(value)
+	if err != nil {
 		return nil, err
 	}
-
-	ecksum := pk.Bytes()
 	if len(ecksum) < 4 {
 		return nil, errors.New("invalid base58 code")
 	}
 	encoded := ecksum[:len(ecksum)-4]
 	cksum := ecksum[len(ecksum)-4:]
 
-	buffer := make([]byte, 0, len(encoded))
-	for i := 0; i < len(value) && value[i] == '1'; i++ {
-		buffer = append(buffer, 0)
+	zeros := 0
+	for zeros < len(value) && value[zeros] == '1' {
+		zeros++
 	}
-	buffer = append(buffer, encoded...)
+	buffer := make([]byte, zeros+len(encoded))
+	copy(buffer[zeros:], encoded)
 
 	//Perform SHA-256 twice
 	hash := sha256.Sum256(buffer)
 	hash = sha256.Sum256(hash[:])
 
-	var err error
 	if !bytes.Equal(hash[:4], cksum) {
 		err = fmt.Errorf("%s checksum did not match to the embeded one:%s, should be :%s",
 			value, hex.EncodeToString(hash[:4]), hex.EncodeToString(cksum))
@@ -95,32 +104,87 @@ func Decode58(value string) ([]byte, error) {
 	return buffer, err
 }
 
-func decodeToBig(src []byte, n *big.Int) error {
-	radix := big.NewInt(58)
-	for i, s := range src {
-		b := decodeMap[s]
-		if b == 0xff {
-			return fmt.Errorf("wrong data at %d", i)
+//decodeDigits returns big-endian bytes without leading zeros of
+//the number represented by base58 src.
+func decodeDigits(src string) ([]byte, error) {
+	//little-endian limbs in radix 2^32.
+	limbs := make([]uint32, 0, len(src)*733/1000/4+1)
+	for i := 0; i < len(src); {
+		n := len(src) - i
+		if n > 5 {
+			n = 5
+		}
+		var carry uint64
+		for j := 0; j < n; j++ {
+			b := decodeMap[src[i+j]]
+			if b == 0xff {
+				return nil, fmt.Errorf("wrong data at %d", i+j)
+			}
+			carry = carry*58 + uint64(b)
+		}
+		mul := pow58[n]
+		for k := range limbs {
+			x := uint64(limbs[k])*mul + carry
+			limbs[k] = uint32(x)
+			carry = x >> 32
+		}
+		if carry != 0 {
+			limbs = append(limbs, uint32(carry))
 		}
-		n.Mul(n, radix).Add(n, big.NewInt(int64(b)))
+		i += n
 	}
-	return nil
+	dst := make([]byte, 4*len(limbs))
+	for k, l := range limbs {
+		j := len(dst) - 4*k
+		dst[j-1] = byte(l)
+		dst[j-2] = byte(l >> 8)
+		dst[j-3] = byte(l >> 16)
+		dst[j-4] = byte(l >> 24)
+	}
+	i := 0
+	for i < len(dst) && dst[i] == 0 {
+		i++
+	}
+	return dst[i:], nil
 }
 
-func encodeBig(src *big.Int) []byte {
-	n := new(big.Int)
-	n.Set(src)
-	radix := big.NewInt(58)
-	zero := big.NewInt(0)
-	var dst []byte
-
-	for i := 0; n.Cmp(zero) > 0; i++ {
-		mod := new(big.Int)
-		n.DivMod(n, radix, mod)
-		dst = append(dst, alphabet[mod.Int64()])
+//encodeDigits returns base58 digits without leading zeros of
+//the number represented by big-endian src.
+func encodeDigits(src []byte) []byte {
+	//little-endian limbs in radix 58^5.
+	limbs := make([]uint32, 0, len(src)*138/100/5+1)
+	for i := 0; i < len(src); {
+		n := (len(src) - i) % 4
+		if n == 0 {
+			n = 4
+		}
+		var carry uint64
+		for j := 0; j < n; j++ {
+			carry = carry<<8 | uint64(src[i+j])
+		}
+		shift := uint(8 * n)
+		for k := range limbs {
+			x := uint64(limbs[k])<<shift + carry
+			limbs[k] = uint32(x % radix58)
+			carry = x / radix58
+		}
+		for carry != 0 {
+			limbs = append(limbs, uint32(carry%radix58))
+			carry /= radix58
+		}
+		i += n
+	}
+	dst := make([]byte, 5*len(limbs))
+	for k, l := range limbs {
+		j := len(dst) - 5*k
+		for m := 1; m <= 5; m++ {
+			dst[j-m] = alphabet[l%58]
+			l /= 58
+		}
 	}
-	for i, j := 0, len(dst)-1; i < j; i, j = i+1, j-1 {
-		dst[i], dst[j] = dst[j], dst[i]
+	i := 0
+	for i < len(dst) && dst[i] == '1' {
+		i++
 	}
-	return dst
+	return dst[i:]
 }
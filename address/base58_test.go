@@ -22,11 +22,135 @@ package address
 
 import (
 	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"math/big"
+	mrand "math/rand"
 	"testing"
 )
 
+//encode58Big and decode58Big are the former implementations with math/big,
+//kept as references.
+func encode58Big(encoded []byte) string {
+	out := make([]byte, len(encoded)+4)
+	copy(out, encoded)
+	hash := sha256.Sum256(encoded)
+	hash = sha256.Sum256(hash[:])
+	copy(out[len(encoded):], hash[0:4])
+	n := new(big.Int).SetBytes(out)
+	radix := big.NewInt(58)
+	zero := big.NewInt(0)
+	var cksum []byte
+	for n.Cmp(zero) > 0 {
+		mod := new(big.Int)
+		n.DivMod(n, radix, mod)
+		cksum = append([]byte{alphabet[mod.Int64()]}, cksum...)
+	}
+	buffer := make([]byte, 0, len(cksum))
+	for i := 0; i < len(encoded) && encoded[i] == 0; i++ {
+		buffer = append(buffer, '1')
+	}
+	buffer = append(buffer, cksum...)
+	return string(buffer)
+}
+
+func decode58Big(value string) ([]byte, error) {
+	if len(value) < 5 {
+		return nil, errors.New("invalid input")
+	}
+	var pk big.Int
+	radix := big.NewInt(58)
+	for i := 0; i < len(value); i++ {
+		b := decodeMap[value[i]]
+		if b == 0xff {
+			return nil, fmt.Errorf("wrong data at %d", i)
+		}
+		pk.Mul(&pk, radix).Add(&pk, big.NewInt(int64(b)))
+	}
+	ecksum := pk.Bytes()
+	if len(ecksum) < 4 {
+		return nil, errors.New("invalid base58 code")
+	}
+	encoded := ecksum[:len(ecksum)-4]
+	cksum := ecksum[len(ecksum)-4:]
+	buffer := make([]byte, 0, len(encoded))
+	for i := 0; i < len(value) && value[i] == '1'; i++ {
+		buffer = append(buffer, 0)
+	}
+	buffer = append(buffer, encoded...)
+	hash := sha256.Sum256(buffer)
+	hash = sha256.Sum256(hash[:])
+	var err error
+	if !bytes.Equal(hash[:4], cksum) {
+		err = fmt.Errorf("%s checksum did not match to the embeded one:%s, should be :%s",
+			value, hex.EncodeToString(hash[:4]), hex.EncodeToString(cksum))
+	}
+	return buffer, err
+}
+
+func errString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
+
+func compareDecode58(t *testing.T, s string) {
+	d1, err1 := Decode58(s)
+	d2, err2 := decode58Big(s)
+	if !bytes.Equal(d1, d2) || errString(err1) != errString(err2) {
+		t.Error("decoding differs for", s, hex.EncodeToString(d1), hex.EncodeToString(d2), err1, err2)
+	}
+}
+
+func TestBase58Diff(t *testing.T) {
+	for i := 0; i < 3000; i++ {
+		dat := make([]byte, mrand.Intn(80))
+		if _, err := rand.Read(dat); err != nil {
+			t.Fatal(err)
+		}
+		for j := 0; j < len(dat) && j < mrand.Intn(5); j++ {
+			dat[j] = 0
+		}
+		e1 := Encode58(dat)
+		e2 := encode58Big(dat)
+		if e1 != e2 {
+			t.Fatal("encoding differs for", hex.EncodeToString(dat), e1, e2)
+		}
+		compareDecode58(t, e1)
+		if len(e1) == 0 {
+			continue
+		}
+		//broken checksum
+		b := []byte(e1)
+		b[mrand.Intn(len(b))] = alphabet[mrand.Intn(len(alphabet))]
+		compareDecode58(t, string(b))
+		//invalid char
+		b[mrand.Intn(len(b))] = "0OIl+/ "[mrand.Intn(7)]
+		compareDecode58(t, string(b))
+	}
+	for i := 0; i <= 64; i++ {
+		dat := make([]byte, i)
+		if e1, e2 := Encode58(dat), encode58Big(dat); e1 != e2 {
+			t.Fatal("encoding differs for", hex.EncodeToString(dat), e1, e2)
+		}
+		for _, c := range []string{"", "1", "2", "z"} {
+			s := string(bytes.Repeat([]byte{'1'}, i)) + c
+			compareDecode58(t, s)
+			compareDecode58(t, c+s)
+		}
+	}
+}
+
+func TestBase58InvalidByte(t *testing.T) {
+	if _, err := Decode58("1111\xff"); err == nil {
+		t.Error("should be error")
+	}
+}
+
 func TestBase58(t *testing.T) {
 	from := "00010966776006953D5567439E5E39F86A0D273BEE"
 	to := "16UwLL9Risc3QfPqBUvKofHmBQ7wMtjvM"
@@ -130,3 +254,41 @@ func BenchmarkBase58(b *testing.B) {
 		}
 	}
 }
+
+var benchData = []byte{
+	0x2e, 0x86, 0x8a, 0x3c, 0x7d, 0x55, 0x12, 0x8e, 0x40, 0x98, 0xbe, 0x9e, 0x3c, 0x93, 0x0b, 0x1a,
+	0x10, 0x49, 0x93, 0x66, 0x9b, 0x60, 0x64, 0x6b, 0xae, 0x7f, 0xaa, 0x21, 0xcc, 0x5f, 0x47, 0x25,
+	0x99, 0x01,
+}
+
+func BenchmarkEncode58(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		Encode58(benchData)
+	}
+}
+
+func BenchmarkEncode58Big(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		encode58Big(benchData)
+	}
+}
+
+func BenchmarkDecode58(b *testing.B) {
+	s := Encode58(benchData)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Decode58(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkDecode58Big(b *testing.B) {
+	s := Encode58(benchData)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := decode58Big(s); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
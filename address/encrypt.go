@@ -21,41 +21,198 @@
 package address
 
 import (
+	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha256"
 	"errors"
+
+	"golang.org/x/crypto/scrypt"
 )
 
-//EncryptSeed encrypts by AES256  with MAC.
-func EncryptSeed(pt []byte, pwd []byte) []byte {
-	pwd256 := sha256.Sum256(pwd)
-	hkey := sha256.Sum256(pwd256[:])
-	block, err := aes.NewCipher(pwd256[:])
+//header of encrypted seeds with a KDF.
+var seedMagic = []byte{0xAD, 0x5E, 0xED}
+
+//version of encrypted seeds with scrypt.
+const seedVersionScrypt = 0x01
+
+const (
+	saltSize   = 16
+	headerSize = 3 + 1 + 3 + saltSize
+)
+
+//KDFParam is a set of cost parameters of scrypt for encrypting seeds.
+type KDFParam struct {
+	LogN byte //N=2^LogN
+	R    byte
+	P    byte
+}
+
+//maxKDFMemory is the max memory used by scrypt, i.e. 128*R*N bytes.
+//Params are read from encrypted seeds which may be broken or crafted,
+//so they must be limited not to exhaust memory and time.
+const maxKDFMemory = 1 << 30
+
+//DefaultKDFParam returns the KDFParam used by EncryptSeed and HDSeed58.
+func DefaultKDFParam() *KDFParam {
+	return &KDFParam{
+		LogN: 16,
+		R:    8,
+		P:    1,
+	}
+}
+
+func (p *KDFParam) check() error {
+	if p.LogN < 1 || p.LogN > 20 {
+		return errors.New("LogN must be between 1 and 20")
+	}
+	if p.R < 1 || p.R > 16 {
+		return errors.New("R must be between 1 and 16")
+	}
+	if p.P < 1 || p.P > 4 {
+		return errors.New("P must be between 1 and 4")
+	}
+	if 128*uint64(p.R)<<p.LogN > maxKDFMemory {
+		return errors.New("scrypt with the params needs too much memory")
+	}
+	return nil
+}
+
+//keys returns keys for AES and HMAC derived from pwd.
+func (p *KDFParam) keys(pwd, salt []byte) ([]byte, []byte, error) {
+	if err := p.check(); err != nil {
+		return nil, nil, err
+	}
+	key, err := scrypt.Key(pwd, salt, 1<<p.LogN, int(p.R), int(p.P), 64)
 	if err != nil {
+		return nil, nil, err
+	}
+	return key[:32], key[32:], nil
+}
+
+//encryptKDF encrypts pt by AES256 with MAC and a key derived by scrypt.
+//The result is header|ciphertext|MAC, and header is
+//magic(3)|version(1)|LogN(1)|R(1)|P(1)|salt(16).
+func encryptKDF(pt, pwd []byte, p *KDFParam) ([]byte, error) {
+	ct := make([]byte, headerSize+len(pt))
+	copy(ct, seedMagic)
+	ct[3] = seedVersionScrypt
+	ct[4], ct[5], ct[6] = p.LogN, p.R, p.P
+	salt := ct[7:headerSize]
+	if _, err := rand.Read(salt); err != nil {
 		panic(err)
 	}
-	ct := make([]byte, aes.BlockSize+len(pt))
-	iv := ct[:aes.BlockSize]
-	if _, err := rand.Read(iv); err != nil {
+	key, hkey, err := p.keys(pwd, salt)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
 		panic(err)
 	}
+	//key is unique for each salt, so it is safe to use a fixed IV.
+	iv := make([]byte, aes.BlockSize)
 	encryptStream := cipher.NewCTR(block, iv)
-	encryptStream.XORKeyStream(ct[aes.BlockSize:], pt)
-	mac := hmac.New(sha256.New, hkey[:])
+	encryptStream.XORKeyStream(ct[headerSize:], pt)
+	mac := hmac.New(sha256.New, hkey)
 	if _, err := mac.Write(ct); err != nil {
 		panic(err)
 	}
-	return mac.Sum(ct)
+	return mac.Sum(ct), nil
+}
+
+//isKDFEncrypted returns true if ct is encrypted by encryptKDF.
+func isKDFEncrypted(ct []byte) bool {
+	return len(ct) >= headerSize+32 && bytes.Equal(ct[:3], seedMagic) &&
+		ct[3] == seedVersionScrypt
+}
+
+func decryptKDF(ct, pwd []byte) ([]byte, error) {
+	if !isKDFEncrypted(ct) {
+		return nil, errors.New("invalid format of encrypted seed")
+	}
+	p := &KDFParam{
+		LogN: ct[4],
+		R:    ct[5],
+		P:    ct[6],
+	}
+	key, hkey, err := p.keys(pwd, ct[7:headerSize])
+	if err != nil {
+		return nil, err
+	}
+	dat := ct[:len(ct)-32]
+	mac := hmac.New(sha256.New, hkey)
+	if _, err := mac.Write(dat); err != nil {
+		panic(err)
+	}
+	if !hmac.Equal(mac.Sum(nil), ct[len(ct)-32:]) {
+		return nil, errors.New("invalid password")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		panic(err)
+	}
+	pt := make([]byte, len(dat)-headerSize)
+	iv := make([]byte, aes.BlockSize)
+	decryptStream := cipher.NewCTR(block, iv)
+	decryptStream.XORKeyStream(pt, dat[headerSize:])
+	return pt, nil
+}
+
+//EncryptSeed encrypts by AES256  with MAC and a key derived from pwd
+//by scrypt with DefaultKDFParam.
+func EncryptSeed(pt []byte, pwd []byte) []byte {
+	ct, err := encryptKDF(pt, pwd, DefaultKDFParam())
+	if err != nil {
+		panic(err)
+	}
+	return ct
+}
+
+//EncryptSeedWithParam encrypts by AES256  with MAC and a key derived from pwd
+//by scrypt with param p.
+func EncryptSeedWithParam(pt []byte, pwd []byte, p *KDFParam) ([]byte, error) {
+	return encryptKDF(pt, pwd, p)
 }
 
 //DecryptSeed decrypts by AES256  with MAC.
+//It accepts both of seeds encrypted by EncryptSeed and ones in the legacy format.
 func DecryptSeed(ct []byte, pwd []byte) ([]byte, error) {
 	if pwd == nil {
 		return nil, errors.New("password is nil")
 	}
+	if !isKDFEncrypted(ct) {
+		return decryptLegacy(ct, pwd)
+	}
+	pt, err := decryptKDF(ct, pwd)
+	if err == nil {
+		return pt, nil
+	}
+	//a legacy seed could start with the magic by accident,
+	//but the error of the KDF is more likely to be the cause.
+	if pt, err2 := decryptLegacy(ct, pwd); err2 == nil {
+		return pt, nil
+	}
+	return nil, err
+}
+
+//UpgradeSeed decrypts ct which may be in the legacy format and
+//re-encrypts it by scrypt with param p and the same password.
+func UpgradeSeed(ct []byte, pwd []byte, p *KDFParam) ([]byte, error) {
+	pt, err := DecryptSeed(ct, pwd)
+	if err != nil {
+		return nil, err
+	}
+	return encryptKDF(pt, pwd, p)
+}
+
+//decryptLegacy decrypts by AES256 with MAC in the legacy format.
+func decryptLegacy(ct []byte, pwd []byte) ([]byte, error) {
+	if len(ct) < aes.BlockSize+32 {
+		return nil, errors.New("invalid length")
+	}
 	pwd256 := sha256.Sum256(pwd)
 	hkey := sha256.Sum256(pwd256[:])
 	dat := ct[:len(ct)-32]
@@ -22,7 +22,11 @@ package address
 
 import (
 	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"testing"
 )
@@ -49,3 +53,104 @@ func TestEnc(t *testing.T) {
 		t.Error("invalid enc and dec")
 	}
 }
+
+//encryptLegacy encrypts by AES256 with MAC in the legacy format.
+//iv is filled with random bytes if it is nil.
+func encryptLegacy(pt []byte, pwd []byte, iv []byte) []byte {
+	pwd256 := sha256.Sum256(pwd)
+	hkey := sha256.Sum256(pwd256[:])
+	block, err := aes.NewCipher(pwd256[:])
+	if err != nil {
+		panic(err)
+	}
+	ct := make([]byte, aes.BlockSize+len(pt))
+	if iv != nil {
+		copy(ct, iv)
+	} else if _, err := rand.Read(ct[:aes.BlockSize]); err != nil {
+		panic(err)
+	}
+	encryptStream := cipher.NewCTR(block, ct[:aes.BlockSize])
+	encryptStream.XORKeyStream(ct[aes.BlockSize:], pt)
+	mac := hmac.New(sha256.New, hkey[:])
+	if _, err := mac.Write(ct); err != nil {
+		panic(err)
+	}
+	return mac.Sum(ct)
+}
+
+func TestEncLegacy(t *testing.T) {
+	pt := make([]byte, 32)
+	if _, err := rand.Read(pt); err != nil {
+		t.Error(err)
+	}
+	pwd := []byte("qewrty123")
+	p := &KDFParam{LogN: 10, R: 8, P: 1}
+	//the latter is a legacy seed which starts with the magic by accident.
+	iv := append(append([]byte{}, seedMagic...), seedVersionScrypt, 10, 8, 1)
+	iv = append(iv, make([]byte, aes.BlockSize-len(iv))...)
+	for _, iv := range [][]byte{nil, iv} {
+		enc := encryptLegacy(pt, pwd, iv)
+		r, err := DecryptSeed(enc, pwd)
+		if err != nil {
+			t.Error(err)
+		}
+		if !bytes.Equal(r, pt) {
+			t.Error("invalid enc and dec")
+		}
+		if _, err := DecryptSeed(enc, []byte{0}); err == nil {
+			t.Error("invalid enc and dec")
+		}
+		enc2, err := UpgradeSeed(enc, pwd, p)
+		if err != nil {
+			t.Error(err)
+		}
+		if !isKDFEncrypted(enc2) {
+			t.Error("not upgraded")
+		}
+		r, err = DecryptSeed(enc2, pwd)
+		if err != nil {
+			t.Error(err)
+		}
+		if !bytes.Equal(r, pt) {
+			t.Error("invalid upgrade")
+		}
+		if _, err := UpgradeSeed(enc, []byte{0}, p); err == nil {
+			t.Error("should be error")
+		}
+	}
+	if _, err := DecryptSeed(make([]byte, 10), pwd); err == nil {
+		t.Error("should be error")
+	}
+	if _, err := EncryptSeedWithParam(pt, pwd, &KDFParam{LogN: 10, R: 0, P: 1}); err == nil {
+		t.Error("should be error")
+	}
+}
+
+func TestDecryptKDFError(t *testing.T) {
+	pt := make([]byte, 32)
+	pwd := []byte("qewrty123")
+	enc, err := EncryptSeedWithParam(pt, pwd, &KDFParam{LogN: 10, R: 8, P: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = DecryptSeed(enc, []byte("wrong"))
+	if err == nil || err.Error() != "invalid password" {
+		t.Error("invalid error", err)
+	}
+	//params which need too much memory or time.
+	for _, p := range [][]byte{{24, 32, 1}, {20, 16, 1}, {16, 8, 16}, {21, 1, 1}} {
+		enc2 := append([]byte{}, enc...)
+		copy(enc2[4:7], p)
+		_, err = DecryptSeed(enc2, pwd)
+		if err == nil || err.Error() == "invalid password" {
+			t.Error("should be error of params", p, err)
+		}
+	}
+	if p := DefaultKDFParam(); p.check() != nil {
+		t.Error("invalid default param")
+	}
+	DefaultKDFParam().LogN = 1
+	if DefaultKDFParam().LogN != 16 {
+		t.Error("default param should not be changed")
+	}
+}
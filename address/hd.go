@@ -59,38 +59,100 @@ func HDseed(masterkey []byte, indices ...uint32) []byte {
 	return private
 }
 
-//HDSeed58 returns base58-encoded encrypted seed..
+//HDSeed58 returns base58-encoded encrypted seed.
+//The seed is encrypted by a key derived from pwd by scrypt with DefaultKDFParam.
 func HDSeed58(conf *aklib.Config, seed, pwd []byte, isNode bool) string {
-	out := make([]byte, len(seed)+4)
-	copy(out, seed)
-	hash := sha256.Sum256(seed)
-	hash = sha256.Sum256(hash[:])
+	s58, err := HDSeed58WithParam(conf, seed, pwd, isNode, DefaultKDFParam())
+	if err != nil {
+		panic(err)
+	}
+	return s58
+}
 
-	copy(out[len(seed):], hash[0:4])
-	eseed := enc(out, pwd)
+//HDSeed58WithParam returns base58-encoded encrypted seed.
+//The seed is encrypted by a key derived from pwd by scrypt with param p.
+func HDSeed58WithParam(conf *aklib.Config, seed, pwd []byte, isNode bool, p *KDFParam) (string, error) {
+	eseed, err := encryptKDF(seed, pwd, p)
+	if err != nil {
+		return "", err
+	}
+	return hdSeed58(conf, eseed, isNode)
+}
 
-	pref := prefixPriv(conf, isNode)
+func hdSeed58(conf *aklib.Config, eseed []byte, isNode bool) (string, error) {
+	pref, err := seedPrefix(conf, isNode, len(eseed))
+	if err != nil {
+		return "", err
+	}
 	s := make([]byte, len(eseed)+len(pref))
 	copy(s, pref)
 	copy(s[len(pref):], eseed)
 	if !isNode {
-		return prefixPrivString + Encode58(s)
+		return prefixPrivString + Encode58(s), nil
 	}
-	return prefixNkeyString + Encode58(s)
+	return prefixNkeyString + Encode58(s), nil
 }
 
 //HDFrom58 returns seed bytes from base58-encoded seed and its password.
+//It accepts both of seeds encoded by HDSeed58 and ones in the legacy format.
 func HDFrom58(cfg *aklib.Config, seed58 string, pwd []byte) ([]byte, bool, error) {
 	if len(seed58) <= len(prefixPrivString) {
 		return nil, false, errors.New("invalid length")
 	}
-	prefixStr := seed58[:len(prefixPrivString)]
+	var isNode bool
+	switch seed58[:len(prefixPrivString)] {
+	case prefixPrivString:
+	case prefixNkeyString:
+		isNode = true
+	default:
+		return nil, false, errors.New("invalid prefix")
+	}
 	eseed, err := Decode58(seed58[len(prefixPrivString):])
 	if err != nil {
 		return nil, false, err
 	}
-	prefix := eseed[:len(cfg.PrefixNode)]
+	if len(eseed) <= len(cfg.PrefixPriv) {
+		return nil, false, errors.New("invalid length")
+	}
+	prefix := eseed[:len(cfg.PrefixPriv)]
 	eseed = eseed[len(cfg.PrefixPriv):]
+	p, err := seedPrefix(cfg, isNode, len(eseed))
+	if err != nil {
+		return nil, false, err
+	}
+	if !bytes.Equal(prefix, p) {
+		return nil, false, errors.New("invalid prefix")
+	}
+	var encoded []byte
+	if isLegacyHDSeed(eseed) {
+		encoded, err = decLegacy(eseed, pwd)
+	} else {
+		encoded, err = decryptKDF(eseed, pwd)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return encoded, isNode, nil
+}
+
+//UpgradeHDSeed58 decodes seed58 which may be in the legacy format and
+//re-encodes it by scrypt with param p and the same password.
+func UpgradeHDSeed58(cfg *aklib.Config, seed58 string, pwd []byte, p *KDFParam) (string, error) {
+	seed, isNode, err := HDFrom58(cfg, seed58, pwd)
+	if err != nil {
+		return "", err
+	}
+	return HDSeed58WithParam(cfg, seed, pwd, isNode, p)
+}
+
+//isLegacyHDSeed returns true if eseed (without a prefix) is encoded
+//in the legacy format, i.e. 32 bytes seed + 4 bytes checksum.
+func isLegacyHDSeed(eseed []byte) bool {
+	return len(eseed) == legacySeedSize
+}
+
+//decLegacy decrypts eseed encoded in the legacy format.
+func decLegacy(eseed, pwd []byte) ([]byte, error) {
 	seed := enc(eseed, pwd)
 	encoded := seed[:len(seed)-4]
 	cksum := seed[len(seed)-4:]
@@ -99,13 +161,7 @@ func HDFrom58(cfg *aklib.Config, seed58 string, pwd []byte) ([]byte, bool, error
 	hash := sha256.Sum256(encoded)
 	hash = sha256.Sum256(hash[:])
 	if !bytes.Equal(hash[:4], cksum) {
-		return nil, false, errors.New("invalid password")
-	}
-	if prefixStr == prefixNkeyString && bytes.Equal(prefix, cfg.PrefixNkey) {
-		return encoded, true, nil
-	}
-	if prefixStr == prefixPrivString && bytes.Equal(prefix, cfg.PrefixPriv) {
-		return encoded, false, nil
+		return nil, errors.New("invalid password")
 	}
-	return nil, false, errors.New("invalid prefix")
+	return encoded, nil
 }
@@ -23,6 +23,7 @@ package address
 import (
 	"bytes"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"log"
 	"strings"
@@ -94,6 +95,20 @@ func TestAddressSeed(t *testing.T) {
 	t.Log("address", a.Address58(aklib.DebugConfig))
 }
 
+//legacyHDSeed58 returns base58-encoded encrypted seed in the legacy format.
+func legacyHDSeed58(conf *aklib.Config, seed, pwd []byte, isNode bool) string {
+	out := make([]byte, len(seed)+4)
+	copy(out, seed)
+	hash := sha256.Sum256(seed)
+	hash = sha256.Sum256(hash[:])
+	copy(out[len(seed):], hash[0:4])
+	s58, err := hdSeed58(conf, enc(out, pwd), isNode)
+	if err != nil {
+		panic(err)
+	}
+	return s58
+}
+
 func testHDSeed(t *testing.T, net *aklib.Config, adr string, fr bool) {
 	seed := GenerateSeed32()
 	pwd1 := []byte("qewrty123")
@@ -102,18 +117,55 @@ func testHDSeed(t *testing.T, net *aklib.Config, adr string, fr bool) {
 	if !strings.HasPrefix(s58, adr) {
 		t.Error("invaild prefix", s58, adr)
 	}
-	_, _, err := HDFrom58(net, s58, []byte("wrong"))
-	if err == nil {
-		t.Error("invalid from58")
+	l58 := legacyHDSeed58(net, seed, pwd1, fr)
+	t.Log(l58)
+	if !strings.HasPrefix(l58, adr) {
+		t.Error("invaild prefix", l58, adr)
+	}
+	for _, s := range []string{s58, l58} {
+		_, _, err := HDFrom58(net, s, []byte("wrong"))
+		if err == nil {
+			t.Error("invalid from58")
+		}
+		rec, fr2, err := HDFrom58(net, s, pwd1)
+		if err != nil {
+			t.Error(err)
+		}
+		if fr != fr2 {
+			t.Error("invalid type")
+		}
+		if !bytes.Equal(rec, seed) {
+			t.Error("invalid from58")
+		}
+	}
+}
+
+func TestUpgradeHDSeed58(t *testing.T) {
+	p := &KDFParam{LogN: 10, R: 8, P: 1}
+	seed := GenerateSeed32()
+	pwd := []byte("qewrty123")
+	l58 := legacyHDSeed58(aklib.MainConfig, seed, pwd, true)
+	if _, err := UpgradeHDSeed58(aklib.MainConfig, l58, []byte("wrong"), p); err == nil {
+		t.Error("should be error")
+	}
+	s58, err := UpgradeHDSeed58(aklib.MainConfig, l58, pwd, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s58 == l58 || !strings.HasPrefix(s58, "AKNKEYM") {
+		t.Error("not upgraded", s58)
 	}
-	rec, fr2, err := HDFrom58(net, s58, pwd1)
+	rec, isNode, err := HDFrom58(aklib.MainConfig, s58, pwd)
 	if err != nil {
 		t.Error(err)
 	}
-	if fr != fr2 {
-		t.Error("invalid type")
+	if !isNode || !bytes.Equal(rec, seed) {
+		t.Error("invalid upgrade")
+	}
+	if _, _, err := HDFrom58(aklib.TestConfig, s58, pwd); err == nil {
+		t.Error("should be error")
 	}
-	if !bytes.Equal(rec, seed) {
-		t.Error("invalid from58")
+	if _, err := HDSeed58WithParam(aklib.MainConfig, seed, pwd, false, &KDFParam{LogN: 30, R: 8, P: 1}); err == nil {
+		t.Error("should be error")
 	}
 }
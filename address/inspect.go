@@ -0,0 +1,155 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package address
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+//Kind is a kind of base58 encoded strings.
+type Kind byte
+
+//Kinds of base58 encoded strings.
+const (
+	KindAddress Kind = iota + 1
+	KindNode
+	KindMultisig
+	KindPrivate
+	KindNodeKey
+)
+
+func (k Kind) String() string {
+	switch k {
+	case KindAddress:
+		return "address"
+	case KindNode:
+		return "node address"
+	case KindMultisig:
+		return "multisig address"
+	case KindPrivate:
+		return "private key"
+	case KindNodeKey:
+		return "node key"
+	default:
+		return ""
+	}
+}
+
+func (k Kind) prefixString() string {
+	switch k {
+	case KindAddress:
+		return prefixAdrsString
+	case KindNode:
+		return prefixNodeString
+	case KindMultisig:
+		return prefixMsigString
+	case KindPrivate:
+		return prefixPrivString
+	case KindNodeKey:
+		return prefixNkeyString
+	default:
+		return ""
+	}
+}
+
+//prefix returns the prefix of k in cfg, which is followed by n bytes.
+func (k Kind) prefix(cfg *aklib.Config, n int) []byte {
+	switch k {
+	case KindAddress:
+		return cfg.PrefixAdrs
+	case KindNode:
+		return cfg.PrefixNode
+	case KindMultisig:
+		return cfg.PrefixMsig
+	case KindPrivate, KindNodeKey:
+		p, err := seedPrefix(cfg, k == KindNodeKey, n)
+		if err != nil {
+			return nil
+		}
+		return p
+	default:
+		return nil
+	}
+}
+
+//Info is a result of Inspect.
+type Info struct {
+	Kind   Kind
+	Config *aklib.Config
+	Bytes  []byte //decoded bytes including the prefix of the network.
+}
+
+//Inspect decodes a base58 encoded address, multisig address, or seed
+//and returns its kind and the network in aklib.Configs.
+func Inspect(s string) (*Info, error) {
+	var kind Kind
+	for k := KindAddress; k <= KindNodeKey; k++ {
+		p := k.prefixString()
+		if len(s) > len(p) && s[:len(p)] == p {
+			kind = k
+			break
+		}
+	}
+	if kind == 0 {
+		return nil, errors.New("unknown type of string")
+	}
+	dat, err := Decode58(s[len(kind.prefixString()):])
+	if err != nil {
+		return nil, err
+	}
+	switch kind {
+	case KindAddress, KindNode, KindMultisig:
+		if len(dat) != 32+2 {
+			return nil, fmt.Errorf("invalid length of %s", kind)
+		}
+	default:
+		if len(dat) <= 2 {
+			return nil, fmt.Errorf("invalid length of %s", kind)
+		}
+	}
+	for _, cfg := range aklib.Configs {
+		if bytes.Equal(dat[:2], kind.prefix(cfg, len(dat)-2)) {
+			return &Info{
+				Kind:   kind,
+				Config: cfg,
+				Bytes:  dat,
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s for unknown network", kind)
+}
+
+//InspectFor decodes a base58 encoded address, multisig address, or seed
+//and returns its kind. It returns an error if the string is not for cfg.
+func InspectFor(cfg *aklib.Config, s string) (*Info, error) {
+	i, err := Inspect(s)
+	if err != nil {
+		return nil, err
+	}
+	if i.Config != cfg {
+		return nil, fmt.Errorf("valid %s %s used on %s", i.Config.Name, i.Kind, cfg.Name)
+	}
+	return i, nil
+}
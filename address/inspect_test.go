@@ -0,0 +1,95 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package address
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+func TestInspect(t *testing.T) {
+	seed := GenerateSeed32()
+	a, err := New(aklib.MainConfig, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := NewNode(aklib.MainConfig, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := &KDFParam{LogN: 10, R: 8, P: 1}
+	for _, cfg := range aklib.Configs {
+		priv, err := HDSeed58WithParam(cfg, seed, nil, false, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		nkey, err := HDSeed58WithParam(cfg, seed, nil, true, p)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, d := range []struct {
+			s    string
+			kind Kind
+			dat  []byte
+		}{
+			{a.Address58(cfg), KindAddress, a.Address(cfg)},
+			{n.Address58(cfg), KindNode, n.Address(cfg)},
+			{MultisigAddress(cfg, 1, a.Address(cfg)), KindMultisig, MultisigAddressByte(cfg, 1, a.Address(cfg))},
+			{priv, KindPrivate, nil},
+			{nkey, KindNodeKey, nil},
+			{legacyHDSeed58(cfg, seed, nil, false), KindPrivate, nil},
+		} {
+			i, err := Inspect(d.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if i.Kind != d.kind || i.Config != cfg {
+				t.Error("invalid inspect", d.s, i.Kind, i.Config.Name)
+			}
+			if d.dat != nil && !bytes.Equal(i.Bytes, d.dat) {
+				t.Error("invalid bytes", d.s)
+			}
+			if _, err := InspectFor(cfg, d.s); err != nil {
+				t.Error(err)
+			}
+		}
+	}
+	_, err = InspectFor(aklib.TestConfig, a.Address58(aklib.MainConfig))
+	if err == nil || err.Error() != "valid mainnet address used on testnet" {
+		t.Error("invalid error", err)
+	}
+	adr := a.Address(aklib.MainConfig)
+	for _, s := range []string{
+		"",
+		"AKADR",
+		"AKXXX" + Encode58(adr),
+		"AKADR" + Encode58(adr[:33]),
+		"AKADR" + Encode58(append([]byte{0, 0}, adr[2:]...)),
+		a.Address58(aklib.MainConfig) + "1",
+		"AKPRI" + Encode58([]byte{0x1d, 0x49}),
+	} {
+		if _, err := Inspect(s); err == nil {
+			t.Error("should be error", s)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package address
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+//messageHash returns a hash of msg prefixed with the magic of the network.
+func messageHash(cfg *aklib.Config, msg []byte) []byte {
+	dat := make([]byte, 4+len(msg))
+	binary.BigEndian.PutUint32(dat, cfg.MessageMagic)
+	copy(dat[4:], msg)
+	h := sha256.Sum256(dat)
+	return h[:]
+}
+
+//SignMessage signs msg for proving the ownership of the address,
+//and returns a base64 encoded signature which includes the public key.
+func (a *Address) SignMessage(cfg *aklib.Config, msg []byte) (string, error) {
+	sig, err := a.Sign(messageHash(cfg, msg))
+	if err != nil {
+		return "", err
+	}
+	dat := make([]byte, 2+len(sig.PublicKey)+len(sig.Sig))
+	binary.BigEndian.PutUint16(dat, uint16(len(sig.PublicKey)))
+	copy(dat[2:], sig.PublicKey)
+	copy(dat[2+len(sig.PublicKey):], sig.Sig)
+	return base64.StdEncoding.EncodeToString(dat), nil
+}
+
+//parseMessageSignature decodes a base64 encoded signature made by SignMessage.
+func parseMessageSignature(sig64 string) (*Signature, error) {
+	dat, err := base64.StdEncoding.DecodeString(sig64)
+	if err != nil {
+		return nil, err
+	}
+	if len(dat) < 2 {
+		return nil, errors.New("invalid length of signature")
+	}
+	l := int(binary.BigEndian.Uint16(dat))
+	if len(dat) <= 2+l {
+		return nil, errors.New("invalid length of signature")
+	}
+	return &Signature{
+		PublicKey: dat[2 : 2+l],
+		Sig:       dat[2+l:],
+	}, nil
+}
+
+//VerifyMessage verifies the base64 encoded signature made by SignMessage
+//for msg and the base58 encoded address adr58 (AKADR or AKNOD).
+func VerifyMessage(cfg *aklib.Config, adr58 string, msg []byte, sig64 string) error {
+	adr, isNode, err := ParseAddress58(cfg, adr58)
+	if err != nil {
+		return err
+	}
+	sig, err := parseMessageSignature(sig64)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(sig.Address(cfg, isNode), adr) {
+		return errors.New("public key in the signature does not match the address")
+	}
+	return sig.Verify(messageHash(cfg, msg))
+}
@@ -0,0 +1,76 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package address
+
+import (
+	"testing"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+func TestSignMessage(t *testing.T) {
+	cfg := aklib.MainConfig
+	msg := []byte("I own this address.")
+	a, err := New(cfg, GenerateSeed32())
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := NewNode(cfg, GenerateSeed32())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, adr := range []*Address{a, n} {
+		sig, err := adr.SignMessage(cfg, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Log(sig)
+		if err := VerifyMessage(cfg, adr.Address58(cfg), msg, sig); err != nil {
+			t.Error(err)
+		}
+		if err := VerifyMessage(cfg, adr.Address58(cfg), []byte("I own this address!"), sig); err == nil {
+			t.Error("should be error")
+		}
+		//MessageMagic of testnet is different from mainnet.
+		if err := VerifyMessage(aklib.TestConfig, adr.Address58(aklib.TestConfig), msg, sig); err == nil {
+			t.Error("should be error")
+		}
+		if err := VerifyMessage(cfg, adr.Address58(cfg), msg, sig[:len(sig)-8]); err == nil {
+			t.Error("should be error")
+		}
+		if err := VerifyMessage(cfg, adr.Address58(cfg), msg, "AAE="); err == nil {
+			t.Error("should be error")
+		}
+		if err := VerifyMessage(cfg, adr.Address58(cfg), msg, "!"+sig); err == nil {
+			t.Error("should be error")
+		}
+	}
+	sig, err := a.SignMessage(cfg, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyMessage(cfg, n.Address58(cfg), msg, sig); err == nil {
+		t.Error("should be error")
+	}
+	if err := VerifyMessage(cfg, "AKADR", msg, sig); err == nil {
+		t.Error("should be error")
+	}
+}
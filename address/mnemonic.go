@@ -0,0 +1,131 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package address
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+)
+
+var wordIndex map[string]int
+
+func init() {
+	wordIndex = make(map[string]int, len(wordsEnglish))
+	for i, w := range wordsEnglish {
+		wordIndex[w] = i
+	}
+}
+
+//NewMnemonic returns a BIP39 mnemonic phrase for the entropy.
+//Length of entropy must be 16, 20, 24, 28 or 32 bytes.
+func NewMnemonic(entropy []byte) (string, error) {
+	if len(entropy) < 16 || len(entropy) > 32 || len(entropy)%4 != 0 {
+		return "", errors.New("invalid length of entropy")
+	}
+	h := sha256.Sum256(entropy)
+	csbits := len(entropy) / 4
+	dat := make([]byte, len(entropy)+1)
+	copy(dat, entropy)
+	dat[len(entropy)] = h[0]
+	nwords := (len(entropy)*8 + csbits) / 11
+	words := make([]string, nwords)
+	for i := range words {
+		idx := 0
+		for j := 0; j < 11; j++ {
+			b := i*11 + j
+			idx <<= 1
+			if dat[b/8]&(0x80>>uint(b%8)) != 0 {
+				idx |= 1
+			}
+		}
+		words[i] = wordsEnglish[idx]
+	}
+	return strings.Join(words, " "), nil
+}
+
+//GenerateMnemonic generates a new 24 words mnemonic phrase.
+func GenerateMnemonic() string {
+	entropy := make([]byte, 32)
+	if _, err := rand.Read(entropy); err != nil {
+		panic(err)
+	}
+	m, err := NewMnemonic(entropy)
+	if err != nil {
+		panic(err)
+	}
+	return m
+}
+
+//MnemonicToEntropy checks the checksum of the mnemonic phrase
+//and returns the entropy.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, errors.New("invalid number of words")
+	}
+	nbits := len(words) * 11
+	csbits := nbits / 33
+	dat := make([]byte, (nbits+7)/8)
+	for i, w := range words {
+		idx, ok := wordIndex[w]
+		if !ok {
+			return nil, fmt.Errorf("unknown word %s at %d", w, i)
+		}
+		for j := 0; j < 11; j++ {
+			if idx&(1<<uint(10-j)) != 0 {
+				b := i*11 + j
+				dat[b/8] |= 0x80 >> uint(b%8)
+			}
+		}
+	}
+	entropy := dat[:(nbits-csbits)/8]
+	h := sha256.Sum256(entropy)
+	mask := byte(0xff) << uint(8-csbits)
+	if dat[len(entropy)]&mask != h[0]&mask {
+		return nil, errors.New("invalid checksum of mnemonic")
+	}
+	return entropy, nil
+}
+
+//IsValidMnemonic returns true if the mnemonic phrase is valid.
+func IsValidMnemonic(mnemonic string) bool {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err == nil
+}
+
+//MnemonicToMasterKey checks the mnemonic phrase and returns
+//a 64 bytes master key for HDseed, derived with the passphrase
+//in the way of BIP39.
+//passphrase should be normalized in NFKD if it contains non-ASCII chars.
+func MnemonicToMasterKey(mnemonic string, passphrase []byte) ([]byte, error) {
+	if _, err := MnemonicToEntropy(mnemonic); err != nil {
+		return nil, err
+	}
+	m := strings.Join(strings.Fields(mnemonic), " ")
+	salt := append([]byte("mnemonic"), passphrase...)
+	return pbkdf2.Key([]byte(m), salt, 2048, 64, sha512.New), nil
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package address
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+//PathPurpose is the first index of paths for wallets,
+//i.e. wallet addresses are derived at m/PathPurpose/account/chain/index.
+const PathPurpose = 44
+
+//Chains in an account.
+const (
+	ChainReceive uint32 = iota
+	ChainChange
+)
+
+//Path is a derivation path of HDseed.
+type Path []uint32
+
+//NewPath returns a path for the index-th address in the chain of the account.
+func NewPath(account, chain, index uint32) Path {
+	return Path{PathPurpose, account, chain, index}
+}
+
+//ParsePath parses a path string like "m/44/0/1/5".
+func ParsePath(s string) (Path, error) {
+	ps := strings.Split(s, "/")
+	if ps[0] != "m" {
+		return nil, errors.New("path must start with m")
+	}
+	p := make(Path, 0, len(ps)-1)
+	for i, str := range ps[1:] {
+		if len(str) > 1 && str[0] == '0' {
+			return nil, fmt.Errorf("index %d must not have leading zeros", i)
+		}
+		idx, err := strconv.ParseUint(str, 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid index %d: %v", i, err)
+		}
+		p = append(p, uint32(idx))
+	}
+	return p, nil
+}
+
+func (p Path) String() string {
+	ps := make([]string, len(p)+1)
+	ps[0] = "m"
+	for i, idx := range p {
+		ps[i+1] = strconv.FormatUint(uint64(idx), 10)
+	}
+	return strings.Join(ps, "/")
+}
+
+//IsWallet returns true if p is in the layout of wallets,
+//i.e. m/PathPurpose/account/chain/index.
+func (p Path) IsWallet() bool {
+	return len(p) == 4 && p[0] == PathPurpose &&
+		(p[2] == ChainReceive || p[2] == ChainChange)
+}
+
+var errNotWallet = errors.New("path is not in the layout of wallets")
+
+//Account returns the account of the path in the layout of wallets.
+//It returns an error if p is not in the layout.
+func (p Path) Account() (uint32, error) {
+	if !p.IsWallet() {
+		return 0, errNotWallet
+	}
+	return p[1], nil
+}
+
+//Chain returns the chain of the path in the layout of wallets.
+//It returns an error if p is not in the layout.
+func (p Path) Chain() (uint32, error) {
+	if !p.IsWallet() {
+		return 0, errNotWallet
+	}
+	return p[2], nil
+}
+
+//Index returns the index of the path in the layout of wallets.
+//It returns an error if p is not in the layout.
+func (p Path) Index() (uint32, error) {
+	if !p.IsWallet() {
+		return 0, errNotWallet
+	}
+	return p[3], nil
+}
+
+//Seed returns the seed derived from masterkey at the path.
+func (p Path) Seed(masterkey []byte) []byte {
+	return HDseed(masterkey, p...)
+}
+
+//Address returns the Address derived from masterkey at the path.
+func (p Path) Address(cfg *aklib.Config, masterkey []byte) (*Address, error) {
+	return New(cfg, p.Seed(masterkey))
+}
+
+//Address58 returns the base58 encoded address derived from masterkey at the path.
+func (p Path) Address58(cfg *aklib.Config, masterkey []byte) (string, error) {
+	a, err := p.Address(cfg, masterkey)
+	if err != nil {
+		return "", err
+	}
+	return a.Address58(cfg), nil
+}
+
+//AddressFromPath returns the Address derived from masterkey at the path string.
+func AddressFromPath(cfg *aklib.Config, masterkey []byte, path string) (*Address, error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	return p.Address(cfg, masterkey)
+}
+
+//Address58FromPath returns the base58 encoded address derived from masterkey
+//at the path string.
+func Address58FromPath(cfg *aklib.Config, masterkey []byte, path string) (string, error) {
+	p, err := ParsePath(path)
+	if err != nil {
+		return "", err
+	}
+	return p.Address58(cfg, masterkey)
+}
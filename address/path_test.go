@@ -0,0 +1,118 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package address
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+func TestPath(t *testing.T) {
+	for _, s := range []string{"m", "m/0", "m/44/0/1/5", "m/4294967295/2"} {
+		p, err := ParsePath(s)
+		if err != nil {
+			t.Error(err)
+		}
+		if p.String() != s {
+			t.Error("invalid path", p.String(), s)
+		}
+	}
+	for _, s := range []string{"", "M/1", "/1", "m/", "m//1", "m/01", "m/1'", "m/-1", "m/+1", "m/4294967296", "m/a", "n/1"} {
+		if _, err := ParsePath(s); err == nil {
+			t.Error("should be error", s)
+		}
+	}
+	p := NewPath(1, ChainChange, 5)
+	if p.String() != "m/44/1/1/5" {
+		t.Error("invalid path", p)
+	}
+	if !p.IsWallet() {
+		t.Error("invalid wallet path")
+	}
+	acc, err := p.Account()
+	if err != nil || acc != 1 {
+		t.Error("invalid account", acc, err)
+	}
+	ch, err := p.Chain()
+	if err != nil || ch != ChainChange {
+		t.Error("invalid chain", ch, err)
+	}
+	idx, err := p.Index()
+	if err != nil || idx != 5 {
+		t.Error("invalid index", idx, err)
+	}
+	for _, p := range []Path{nil, {44}, {44, 1, 1}, {44, 1, 2, 5}} {
+		if _, err := p.Account(); err == nil {
+			t.Error("should be error", p)
+		}
+		if _, err := p.Chain(); err == nil {
+			t.Error("should be error", p)
+		}
+		if _, err := p.Index(); err == nil {
+			t.Error("should be error", p)
+		}
+	}
+	if (Path{44, 1, 2, 5}).IsWallet() || (Path{43, 1, 1, 5}).IsWallet() || (Path{44, 1, 1}).IsWallet() {
+		t.Error("should not be a wallet path")
+	}
+}
+
+func TestPathAddress(t *testing.T) {
+	cfg := aklib.DebugConfig
+	master := GenerateSeed32()
+	p, err := ParsePath("m/44/0/1/5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(p.Seed(master), HDseed(master, 44, 0, 1, 5)) {
+		t.Error("invalid seed")
+	}
+	a, err := New(cfg, HDseed(master, 44, 0, 1, 5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	a2, err := AddressFromPath(cfg, master, "m/44/0/1/5")
+	if err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(a.PublicKey(), a2.PublicKey()) {
+		t.Error("invalid address")
+	}
+	a58, err := Address58FromPath(cfg, master, "m/44/0/1/5")
+	if err != nil {
+		t.Error(err)
+	}
+	if a58 != a.Address58(cfg) {
+		t.Error("invalid address58")
+	}
+	r58, err := NewPath(0, ChainReceive, 5).Address58(cfg, master)
+	if err != nil {
+		t.Error(err)
+	}
+	if r58 == a58 {
+		t.Error("receive and change addresses must be different")
+	}
+	if _, err := Address58FromPath(cfg, master, "m/x"); err == nil {
+		t.Error("should be error")
+	}
+}
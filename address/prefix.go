@@ -0,0 +1,170 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package address
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+//legacySeedSize is the size of seeds encrypted in the legacy format,
+//i.e. 32 bytes seed + 4 bytes checksum.
+const legacySeedSize = 32 + 4
+
+//seedPrefixes is a cache of prefixes derived by seedPrefix.
+var seedPrefixes sync.Map
+
+//lead58 returns the first 2 chars of base58 strings
+//of prefix followed by n bytes and 4 bytes checksum.
+//It returns an error if the chars depend on the following bytes.
+func lead58(prefix []byte, n int) (string, error) {
+	lo := make([]byte, len(prefix)+n+4)
+	hi := make([]byte, len(prefix)+n+4)
+	copy(lo, prefix)
+	copy(hi, prefix)
+	for i := len(prefix); i < len(hi); i++ {
+		hi[i] = 0xff
+	}
+	l := encodeDigits(lo)
+	h := encodeDigits(hi)
+	if len(l) < 2 || len(l) != len(h) || l[0] != h[0] || l[1] != h[1] {
+		return "", fmt.Errorf("base58 strings with prefix %x do not start with fixed chars", prefix)
+	}
+	return string(l[:2]), nil
+}
+
+//Prefix58 returns the smallest 2 bytes prefix whose base58 strings
+//followed by n bytes and 4 bytes checksum always start with lead,
+//i.e. it computes prefixes like PrefixAdrs in aklib.Config.
+func Prefix58(lead string, n int) ([]byte, error) {
+	if len(lead) != 2 || lead[0] == alphabet[0] ||
+		decodeMap[lead[0]] == 0xff || decodeMap[lead[1]] == 0xff {
+		return nil, errors.New("invalid lead chars")
+	}
+	enc := func(p int, b byte) []byte {
+		dat := make([]byte, 2+n+4)
+		dat[0], dat[1] = byte(p>>8), byte(p)
+		for i := 2; i < len(dat); i++ {
+			dat[i] = b
+		}
+		return encodeDigits(dat)
+	}
+	//base58 strings with the same length are ordered as their numbers,
+	//because the alphabet is in the ASCII order.
+	less := func(a []byte, b string) bool {
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return string(a) < b
+	}
+	const min, max = 0x0100, 0xffff
+	for k := len(enc(min, 0)); k <= len(enc(max, 0xff)); k++ {
+		t := lead + strings.Repeat(alphabet[:1], k-2)
+		p := min + sort.Search(max-min+1, func(i int) bool {
+			return !less(enc(min+i, 0), t)
+		})
+		for ; p <= max; p++ {
+			lo, hi := enc(p, 0), enc(p, 0xff)
+			if len(lo) != k || string(lo[:2]) != lead {
+				break
+			}
+			if len(hi) == k && string(hi[:2]) == lead {
+				return []byte{byte(p >> 8), byte(p)}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no prefix for %s followed by %d bytes", lead, n)
+}
+
+//seedPrefix returns the prefix of base58 encoded seeds whose encrypted size is n.
+//Prefixes in configs are for seeds in the legacy format, so prefixes for
+//other sizes are derived for base58 strings to start with the same chars,
+//e.g. "AKPRIVM" for MainNet.
+func seedPrefix(cfg *aklib.Config, isNode bool, n int) ([]byte, error) {
+	p := prefixPriv(cfg, isNode)
+	if n == legacySeedSize {
+		return p, nil
+	}
+	key := fmt.Sprintf("%x/%d", p, n)
+	if v, ok := seedPrefixes.Load(key); ok {
+		return v.([]byte), nil
+	}
+	lead, err := lead58(p, legacySeedSize)
+	if err != nil {
+		return nil, err
+	}
+	np, err := Prefix58(lead, n)
+	if err != nil {
+		return nil, err
+	}
+	seedPrefixes.Store(key, np)
+	return np, nil
+}
+
+func init() {
+	aklib.SetConfigValidator(ValidateConfig)
+}
+
+//ValidateConfig checks cfg by cfg.Validate and checks that
+//base58 strings with prefixes in cfg always start with the same chars
+//which differ from each other, and that genesis addresses are valid in cfg.
+//Built-in configs except DebugConfig have no valid genesis addresses.
+//It is called by aklib.LoadConfig and aklib.RegisterConfig.
+func ValidateConfig(cfg *aklib.Config) error {
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	for _, ps := range []struct {
+		prefixes [][]byte
+		n        int
+	}{
+		//2 bytes prefix + 32 bytes hash
+		{[][]byte{cfg.PrefixAdrs, cfg.PrefixMsig, cfg.PrefixNode}, 32},
+		{[][]byte{cfg.PrefixPriv, cfg.PrefixNkey}, legacySeedSize},
+	} {
+		leads := make(map[string]struct{})
+		for _, p := range ps.prefixes {
+			l, err := lead58(p, ps.n)
+			if err != nil {
+				return err
+			}
+			if _, ok := leads[l]; ok {
+				return fmt.Errorf("prefix %x starts with the same chars %s as others", p, l)
+			}
+			leads[l] = struct{}{}
+		}
+	}
+	for adr := range cfg.Genesis {
+		_, isNode, err := ParseAddress58(cfg, adr)
+		if err != nil {
+			return fmt.Errorf("invalid genesis address %s: %v", adr, err)
+		}
+		if isNode {
+			return fmt.Errorf("genesis address %s must not be a node address", adr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package address
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+func TestPrefix58(t *testing.T) {
+	for _, d := range []struct {
+		prefix []byte
+		n      int
+		lead   string
+	}{
+		{aklib.MainConfig.PrefixPriv, legacySeedSize, "VM"},
+		{aklib.MainConfig.PrefixAdrs, 32, "SM"},
+		{aklib.TestConfig.PrefixNkey, legacySeedSize, "YT"},
+		{aklib.DebugConfig.PrefixNode, 32, "ED"},
+	} {
+		l, err := lead58(d.prefix, d.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l != d.lead {
+			t.Error("invalid lead", d.prefix, l)
+		}
+	}
+	for _, lead := range []string{"VM", "YT", "SR", "ER", "GR"} {
+		for _, n := range []int{32, legacySeedSize, 87} {
+			p, err := Prefix58(lead, n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l, err := lead58(p, n)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l != lead {
+				t.Error("invalid prefix", lead, n, p)
+			}
+		}
+	}
+	p, err := Prefix58("SM", 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p[0] != aklib.MainConfig.PrefixAdrs[0] {
+		t.Error("invalid prefix", p)
+	}
+	//base58 strings of 38 bytes numbers cannot start with "zz".
+	for _, lead := range []string{"", "S", "SMS", "1M", "S0", "Sl", "zz"} {
+		if _, err := Prefix58(lead, 32); err == nil {
+			t.Error("should be error", lead)
+		}
+	}
+}
+
+func TestSeedPrefix(t *testing.T) {
+	for _, cfg := range aklib.Configs {
+		for _, isNode := range []bool{false, true} {
+			p, err := seedPrefix(cfg, isNode, legacySeedSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(p, prefixPriv(cfg, isNode)) {
+				t.Error("invalid legacy prefix", p)
+			}
+			p2, err := seedPrefix(cfg, isNode, headerSize+32+32)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l, err := lead58(p, legacySeedSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			l2, err := lead58(p2, headerSize+32+32)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if l != l2 {
+				t.Error("invalid prefix", cfg.Name, p2, l, l2)
+			}
+		}
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	if err := ValidateConfig(aklib.DebugConfig); err != nil {
+		t.Error(err)
+	}
+	if err := ValidateConfig(aklib.MainConfig); err == nil {
+		t.Error("should be error")
+	}
+	node, err := Address58(aklib.DebugConfig, append([]byte{0x59, 0x48}, make([]byte, 32)...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := *aklib.DebugConfig
+	c.Name = "debug2"
+	c.PrefixNode = []byte{0xaa, 0x68}
+	if err := aklib.RegisterConfig(&c); err == nil {
+		aklib.Configs = aklib.Configs[:len(aklib.Configs)-1]
+		t.Error("should be error")
+	}
+	for i, f := range []func(c *aklib.Config){
+		func(c *aklib.Config) { c.Name = "" },
+		func(c *aklib.Config) { c.PrefixAdrs = []byte{0x01, 0x00} },
+		func(c *aklib.Config) { c.PrefixNode = []byte{0xaa, 0x68} },
+		func(c *aklib.Config) { c.PrefixNkey = []byte{0x1d, 0x25} },
+		func(c *aklib.Config) {
+			c.Genesis = map[string]uint64{"AKADRSD1": aklib.ADKSupply}
+		},
+		func(c *aklib.Config) {
+			c.Genesis = map[string]uint64{node: aklib.ADKSupply}
+		},
+	} {
+		c := *aklib.DebugConfig
+		f(&c)
+		if err := ValidateConfig(&c); err == nil {
+			t.Error("should be error", i)
+		}
+	}
+}
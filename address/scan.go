@@ -0,0 +1,118 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package address
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+//DefaultGapLimit is the recommended number of consecutive unused addresses
+//for stopping the scan.
+const DefaultGapLimit = 20
+
+//max numbers of addresses in a chain and of accounts walked by the scan,
+//for stopping the scan with a lookup which always returns true.
+var (
+	maxScanIndex   uint32 = 10000
+	maxScanAccount uint32 = 1000
+)
+
+//Lookup is an interface for checking whether an address was used.
+//rpc.RPC implements this by getlasthistory RPC.
+type Lookup interface {
+	IsUsed(adr58 string) (bool, error)
+}
+
+//UsedAddress is an used address found by the scan.
+type UsedAddress struct {
+	Path    Path
+	Address string
+}
+
+//ScanAccount walks the receive and change chains of the account
+//and returns used addresses in them.
+//It stops walking a chain after gap consecutive unused addresses, and
+//returns an error if the chain has too many addresses.
+func ScanAccount(cfg *aklib.Config, masterkey []byte, account uint32, gap uint32, l Lookup) ([]*UsedAddress, error) {
+	if gap == 0 {
+		return nil, errors.New("gap must be over 0")
+	}
+	var used []*UsedAddress
+	for _, chain := range []uint32{ChainReceive, ChainChange} {
+		u, err := scanChain(cfg, masterkey, account, chain, gap, l)
+		if err != nil {
+			return nil, err
+		}
+		used = append(used, u...)
+	}
+	return used, nil
+}
+
+//Scan scans accounts from 0 until an account without any used address,
+//and returns used addresses in the accounts.
+//It returns an error if there are too many accounts.
+func Scan(cfg *aklib.Config, masterkey []byte, gap uint32, l Lookup) ([]*UsedAddress, error) {
+	var used []*UsedAddress
+	for account := uint32(0); ; account++ {
+		if account >= maxScanAccount {
+			return nil, errors.New("too many accounts to scan")
+		}
+		u, err := ScanAccount(cfg, masterkey, account, gap, l)
+		if err != nil {
+			return nil, err
+		}
+		if len(u) == 0 {
+			return used, nil
+		}
+		used = append(used, u...)
+	}
+}
+
+func scanChain(cfg *aklib.Config, masterkey []byte, account, chain, gap uint32, l Lookup) ([]*UsedAddress, error) {
+	var used []*UsedAddress
+	for i, unused := uint32(0), uint32(0); unused < gap; i++ {
+		if i >= maxScanIndex {
+			return nil, fmt.Errorf("too many addresses to scan in account %d chain %d", account, chain)
+		}
+		p := NewPath(account, chain, i)
+		adr, err := p.Address58(cfg, masterkey)
+		if err != nil {
+			return nil, err
+		}
+		ok, err := l.IsUsed(adr)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			unused++
+			continue
+		}
+		unused = 0
+		used = append(used, &UsedAddress{
+			Path:    p,
+			Address: adr,
+		})
+	}
+	return used, nil
+}
@@ -0,0 +1,132 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package address
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+type lookup struct {
+	used   map[string]bool
+	called int
+	err    error
+}
+
+func (l *lookup) IsUsed(adr string) (bool, error) {
+	l.called++
+	return l.used[adr], l.err
+}
+
+func TestScan(t *testing.T) {
+	cfg := aklib.DebugConfig
+	master := GenerateSeed32()
+	paths := []Path{
+		NewPath(0, ChainReceive, 0),
+		NewPath(0, ChainReceive, 3),
+		NewPath(0, ChainReceive, 8),
+		NewPath(0, ChainChange, 1),
+		NewPath(1, ChainReceive, 4),
+		NewPath(3, ChainReceive, 0),  //after the unused account 2
+		NewPath(0, ChainReceive, 15), //after the gap
+	}
+	l := &lookup{
+		used: make(map[string]bool),
+	}
+	for _, p := range paths {
+		adr, err := p.Address58(cfg, master)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.used[adr] = true
+	}
+	used, err := Scan(cfg, master, 5, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(used) != 5 {
+		t.Fatal("invalid number of used addresses", len(used))
+	}
+	for i, u := range used {
+		if u.Path.String() != paths[i].String() {
+			t.Error("invalid path", u.Path, paths[i])
+		}
+		if !l.used[u.Address] {
+			t.Error("invalid address")
+		}
+	}
+	//account0:(9+5)+(2+5), account1:(5+5)+5, account2:5+5
+	if l.called != 46 {
+		t.Error("invalid number of lookups", l.called)
+	}
+
+	used, err = ScanAccount(cfg, master, 0, 7, l)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(used) != 5 {
+		t.Fatal("invalid scan with larger gap", len(used))
+	}
+	if idx, err := used[3].Path.Index(); err != nil || idx != 15 {
+		t.Error("invalid scan with larger gap", idx, err)
+	}
+	if _, err := ScanAccount(cfg, master, 0, 0, l); err == nil {
+		t.Error("should be error")
+	}
+	l.err = errors.New("lookup error")
+	if _, err := Scan(cfg, master, 5, l); err == nil {
+		t.Error("should be error")
+	}
+}
+
+type usedLookup struct{}
+
+func (usedLookup) IsUsed(adr string) (bool, error) {
+	return true, nil
+}
+
+func TestScanLimit(t *testing.T) {
+	cfg := aklib.DebugConfig
+	master := GenerateSeed32()
+	defer func(i, a uint32) {
+		maxScanIndex, maxScanAccount = i, a
+	}(maxScanIndex, maxScanAccount)
+	maxScanIndex, maxScanAccount = 10, 3
+	if _, err := ScanAccount(cfg, master, 0, 5, usedLookup{}); err == nil {
+		t.Error("should be error")
+	}
+	maxScanIndex = 100
+	l := &lookup{
+		used: make(map[string]bool),
+	}
+	for a := uint32(0); a < 4; a++ {
+		adr, err := NewPath(a, ChainReceive, 0).Address58(cfg, master)
+		if err != nil {
+			t.Fatal(err)
+		}
+		l.used[adr] = true
+	}
+	if _, err := Scan(cfg, master, 1, l); err == nil {
+		t.Error("should be error")
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package shamir
+
+//arithmetic in GF(2^8) with the polynomial x^8+x^4+x^3+x+1.
+
+var (
+	expTable [255]byte
+	logTable [256]byte
+)
+
+func init() {
+	x := byte(1)
+	for i := range expTable {
+		expTable[i] = x
+		logTable[x] = byte(i)
+		//multiply by the generator 3.
+		x2 := x << 1
+		if x&0x80 != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+}
+
+func mul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return expTable[(int(logTable[a])+int(logTable[b]))%255]
+}
+
+//div returns a/b. b must not be 0.
+func div(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return expTable[(int(logTable[a])-int(logTable[b])+255)%255]
+}
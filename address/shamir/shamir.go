@@ -0,0 +1,187 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package shamir
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/AidosKuneen/aklib"
+	"github.com/AidosKuneen/aklib/address"
+)
+
+const prefixShareString = "AKSHR"
+
+//SeedSize is the size of a seed to be split.
+const SeedSize = 32
+
+//length of share bytes: prefix(2)|id(4)|threshold(1)|index(1)|value(32)
+//address.Encode58 appends checksum(4), the first 4 bytes of double sha256
+//of the share bytes, which is verified in decode.
+//Nothing derived from the seed itself is stored in shares.
+const shareSize = 2 + 4 + 1 + 1 + SeedSize
+
+//Errors when combining shares.
+var (
+	ErrCorruptedShare   = errors.New("share is corrupted")
+	ErrMismatchedShares = errors.New("shares are not from the same split")
+	ErrDuplicatedShare  = errors.New("shares are duplicated")
+	ErrNotEnoughShares  = errors.New("not enough shares")
+)
+
+type share struct {
+	id        uint32
+	threshold byte
+	index     byte
+	value     []byte
+}
+
+func (s *share) encode(cfg *aklib.Config) string {
+	dat := make([]byte, shareSize)
+	copy(dat, cfg.PrefixPriv)
+	binary.BigEndian.PutUint32(dat[2:], s.id)
+	dat[6] = s.threshold
+	dat[7] = s.index
+	copy(dat[8:], s.value)
+	return prefixShareString + address.Encode58(dat)
+}
+
+func decode(cfg *aklib.Config, s58 string) (*share, error) {
+	if len(s58) <= len(prefixShareString) || s58[:len(prefixShareString)] != prefixShareString {
+		return nil, fmt.Errorf("%w: invalid prefix", ErrCorruptedShare)
+	}
+	//Decode58 verifies the checksum of the share.
+	dat, err := address.Decode58(s58[len(prefixShareString):])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrCorruptedShare, err)
+	}
+	if len(dat) != shareSize {
+		return nil, fmt.Errorf("%w: invalid length", ErrCorruptedShare)
+	}
+	if !bytes.Equal(dat[:2], cfg.PrefixPriv) {
+		return nil, fmt.Errorf("share is not for %s", cfg.Name)
+	}
+	s := &share{
+		id:        binary.BigEndian.Uint32(dat[2:]),
+		threshold: dat[6],
+		index:     dat[7],
+		value:     dat[8:],
+	}
+	if s.index == 0 || s.threshold == 0 {
+		return nil, fmt.Errorf("%w: invalid index or threshold", ErrCorruptedShare)
+	}
+	return s, nil
+}
+
+//Split splits the seed into n shares, any m of which can recover the seed.
+func Split(cfg *aklib.Config, seed []byte, m, n byte) ([]string, error) {
+	if len(seed) != SeedSize {
+		return nil, fmt.Errorf("length of seed must be %d", SeedSize)
+	}
+	if m == 0 || m > n {
+		return nil, errors.New("m must be between 1 and n")
+	}
+	var id [4]byte
+	coef := make([]byte, int(m-1)*SeedSize)
+	if _, err := rand.Read(id[:]); err != nil {
+		panic(err)
+	}
+	if _, err := rand.Read(coef); err != nil {
+		panic(err)
+	}
+	shares := make([]string, n)
+	for i := range shares {
+		s := &share{
+			id:        binary.BigEndian.Uint32(id[:]),
+			threshold: m,
+			index:     byte(i + 1),
+			value:     make([]byte, SeedSize),
+		}
+		for j := range seed {
+			//evaluate seed[j] + coef[0][j]*x + coef[1][j]*x^2 ... by Horner's method.
+			var y byte
+			for k := int(m) - 2; k >= 0; k-- {
+				y = mul(y, s.index) ^ coef[k*SeedSize+j]
+			}
+			s.value[j] = mul(y, s.index) ^ seed[j]
+		}
+		shares[i] = s.encode(cfg)
+	}
+	return shares, nil
+}
+
+//Combine recovers the seed from shares made by Split.
+func Combine(cfg *aklib.Config, shares ...string) ([]byte, error) {
+	if len(shares) == 0 {
+		return nil, ErrNotEnoughShares
+	}
+	ss := make([]*share, len(shares))
+	for i, s58 := range shares {
+		s, err := decode(cfg, s58)
+		if err != nil {
+			return nil, fmt.Errorf("share %d: %w", i, err)
+		}
+		if i > 0 && (s.id != ss[0].id || s.threshold != ss[0].threshold) {
+			return nil, fmt.Errorf("share %d: %w", i, ErrMismatchedShares)
+		}
+		for j := 0; j < i; j++ {
+			if ss[j].index == s.index {
+				return nil, fmt.Errorf("share %d and %d: %w", j, i, ErrDuplicatedShare)
+			}
+		}
+		ss[i] = s
+	}
+	if len(ss) < int(ss[0].threshold) {
+		return nil, fmt.Errorf("%w: %d shares are needed, but %d",
+			ErrNotEnoughShares, ss[0].threshold, len(ss))
+	}
+	th := ss[0].threshold
+	seed := interpolate(ss[:th], 0)
+	//shares over the threshold must lie on the same polynomial.
+	for i, s := range ss[th:] {
+		if !bytes.Equal(interpolate(ss[:th], s.index), s.value) {
+			return nil, fmt.Errorf("share %d: %w: inconsistent with other shares",
+				int(th)+i, ErrCorruptedShare)
+		}
+	}
+	return seed, nil
+}
+
+//interpolate returns values at x of the polynomial which passes all ss.
+func interpolate(ss []*share, x byte) []byte {
+	v := make([]byte, SeedSize)
+	for i, s := range ss {
+		//Lagrange basis polynomial at x
+		l := byte(1)
+		for j, t := range ss {
+			if i != j {
+				l = mul(l, div(t.index^x, t.index^s.index))
+			}
+		}
+		for k := range v {
+			v[k] ^= mul(l, s.value[k])
+		}
+	}
+	return v
+}
@@ -0,0 +1,151 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package shamir
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/AidosKuneen/aklib"
+	"github.com/AidosKuneen/aklib/address"
+)
+
+func TestGF256(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		for b := 1; b < 256; b++ {
+			c := mul(byte(a), byte(b))
+			if div(c, byte(b)) != byte(a) {
+				t.Fatal("invalid mul or div", a, b)
+			}
+		}
+	}
+	if mul(0x57, 0x83) != 0xc1 {
+		t.Error("invalid mul")
+	}
+}
+
+func TestShamir(t *testing.T) {
+	cfg := aklib.MainConfig
+	seed := address.GenerateSeed32()
+	shares, err := Split(cfg, seed, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 5 {
+		t.Fatal("invalid number of shares")
+	}
+	for _, s := range shares {
+		t.Log(s)
+		if !strings.HasPrefix(s, prefixShareString) {
+			t.Error("invalid prefix")
+		}
+	}
+	for _, ss := range [][]string{
+		shares[:3],
+		shares[2:],
+		{shares[4], shares[0], shares[2]},
+		shares,
+	} {
+		s, err := Combine(cfg, ss...)
+		if err != nil {
+			t.Error(err)
+		}
+		if !bytes.Equal(s, seed) {
+			t.Error("invalid combine")
+		}
+	}
+	s1, err := Split(cfg, seed, 1, 1)
+	if err != nil {
+		t.Error(err)
+	}
+	s, err := Combine(cfg, s1...)
+	if err != nil {
+		t.Error(err)
+	}
+	if !bytes.Equal(s, seed) {
+		t.Error("invalid combine")
+	}
+}
+
+func TestShamirError(t *testing.T) {
+	cfg := aklib.MainConfig
+	seed := address.GenerateSeed32()
+	shares, err := Split(cfg, seed, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	shares2, err := Split(cfg, seed, 2, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Combine(cfg, shares[0]); !errors.Is(err, ErrNotEnoughShares) {
+		t.Error("should be ErrNotEnoughShares", err)
+	}
+	if _, err := Combine(cfg); !errors.Is(err, ErrNotEnoughShares) {
+		t.Error("should be ErrNotEnoughShares", err)
+	}
+	if _, err := Combine(cfg, shares[0], shares[0]); !errors.Is(err, ErrDuplicatedShare) {
+		t.Error("should be ErrDuplicatedShare", err)
+	}
+	if _, err := Combine(cfg, shares[0], shares2[1]); !errors.Is(err, ErrMismatchedShares) {
+		t.Error("should be ErrMismatchedShares", err)
+	}
+	c := []byte(shares[1])
+	if c[10] == 'a' {
+		c[10] = 'b'
+	} else {
+		c[10] = 'a'
+	}
+	if _, err := Combine(cfg, shares[0], string(c)); !errors.Is(err, ErrCorruptedShare) {
+		t.Error("should be ErrCorruptedShare", err)
+	}
+	_, err = Combine(cfg, shares[0], shares[2], string(c))
+	if !errors.Is(err, ErrCorruptedShare) || !strings.HasPrefix(err.Error(), "share 2:") {
+		t.Error("should be ErrCorruptedShare at share 2", err)
+	}
+	if _, err := Combine(cfg, shares[0], "AKADR"+shares[1][5:]); !errors.Is(err, ErrCorruptedShare) {
+		t.Error("should be ErrCorruptedShare", err)
+	}
+	//valid checksum but tampered value.
+	sh, err := decode(cfg, shares[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	sh.value[0] ^= 1
+	_, err = Combine(cfg, shares[0], shares[2], sh.encode(cfg))
+	if !errors.Is(err, ErrCorruptedShare) || !strings.HasPrefix(err.Error(), "share 2:") {
+		t.Error("should be ErrCorruptedShare at share 2", err)
+	}
+	if _, err := Combine(aklib.TestConfig, shares[0], shares[1]); err == nil {
+		t.Error("should be error")
+	}
+	if _, err := Split(cfg, seed[:31], 2, 3); err == nil {
+		t.Error("should be error")
+	}
+	if _, err := Split(cfg, seed, 4, 3); err == nil {
+		t.Error("should be error")
+	}
+	if _, err := Split(cfg, seed, 0, 3); err == nil {
+		t.Error("should be error")
+	}
+}
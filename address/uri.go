@@ -0,0 +1,129 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package address
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+//URIScheme is the scheme of payment request URIs.
+const URIScheme = "aidos"
+
+//PaymentRequest is a request for payment which can be encoded into an URI
+//like "aidos:AKADR...?amount=1.5&message=...".
+type PaymentRequest struct {
+	Address string //AKADR or AKMSI address
+	Amount  uint64 //in unit of transactions, 0 means not specified.
+	Message string //for Message in a tx
+	Label   string //label of the address for the payer
+}
+
+func checkPaymentAddress(cfg *aklib.Config, adr string) error {
+	if strings.HasPrefix(adr, prefixMsigString) {
+		_, err := ParseMultisigAddress(cfg, adr)
+		return err
+	}
+	_, isNode, err := ParseAddress58(cfg, adr)
+	if err != nil {
+		return err
+	}
+	if isNode {
+		return errors.New("node address cannot be used for payments")
+	}
+	return nil
+}
+
+func escape(s string) string {
+	return strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+}
+
+//URI returns the URI of the payment request after checking the address.
+func (p *PaymentRequest) URI(cfg *aklib.Config) (string, error) {
+	if err := checkPaymentAddress(cfg, p.Address); err != nil {
+		return "", err
+	}
+	if p.Amount > aklib.ADKSupply {
+		return "", errors.New("amount is over the total supply")
+	}
+	var qs []string
+	if p.Amount != 0 {
+		qs = append(qs, "amount="+aklib.FormatADK(p.Amount))
+	}
+	if p.Label != "" {
+		qs = append(qs, "label="+escape(p.Label))
+	}
+	if p.Message != "" {
+		qs = append(qs, "message="+escape(p.Message))
+	}
+	uri := URIScheme + ":" + p.Address
+	if len(qs) > 0 {
+		uri += "?" + strings.Join(qs, "&")
+	}
+	return uri, nil
+}
+
+//ParseURI parses the URI of a payment request and checks the address.
+//Unknown parameters are ignored unless they start with "req-".
+func ParseURI(cfg *aklib.Config, uri string) (*PaymentRequest, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(u.Scheme, URIScheme) {
+		return nil, fmt.Errorf("scheme must be %s", URIScheme)
+	}
+	p := &PaymentRequest{
+		Address: u.Opaque,
+	}
+	if err := checkPaymentAddress(cfg, p.Address); err != nil {
+		return nil, err
+	}
+	q, err := url.ParseQuery(u.RawQuery)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range q {
+		if len(v) != 1 {
+			return nil, fmt.Errorf("parameter %s must be specified once", k)
+		}
+		switch k {
+		case "amount":
+			p.Amount, err = aklib.ParseADK(v[0])
+			if err != nil {
+				return nil, fmt.Errorf("invalid amount: %v", err)
+			}
+		case "message":
+			p.Message = v[0]
+		case "label":
+			p.Label = v[0]
+		default:
+			if strings.HasPrefix(k, "req-") {
+				return nil, fmt.Errorf("unknown required parameter %s", k)
+			}
+		}
+	}
+	return p, nil
+}
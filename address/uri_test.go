@@ -0,0 +1,105 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package address
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+func TestURI(t *testing.T) {
+	cfg := aklib.MainConfig
+	a, err := New(cfg, GenerateSeed32())
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, err := NewNode(cfg, GenerateSeed32())
+	if err != nil {
+		t.Fatal(err)
+	}
+	adr := a.Address58(cfg)
+	msig := MultisigAddress(cfg, 1, a.Address(cfg))
+	for _, p := range []*PaymentRequest{
+		{Address: adr},
+		{Address: adr, Amount: 150000000},
+		{Address: adr, Amount: 1, Message: "order #1 & 2+3=5", Label: "Shop 日本"},
+		{Address: msig, Amount: aklib.ADKSupply, Label: "treasury"},
+	} {
+		uri, err := p.URI(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Log(uri)
+		if strings.Contains(uri, " ") || strings.Contains(uri, "+") {
+			t.Error("invalid escape", uri)
+		}
+		p2, err := ParseURI(cfg, uri)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *p2 != *p {
+			t.Error("invalid uri", p2, p)
+		}
+	}
+	uri, err := (&PaymentRequest{Address: adr, Amount: 150000000, Message: "hi"}).URI(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uri != "aidos:"+adr+"?amount=1.5&message=hi" {
+		t.Error("invalid uri", uri)
+	}
+	p, err := ParseURI(cfg, "AIDOS:"+adr+"?amount=0.12345678&message=a+b%20c&foo=bar")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Amount != 12345678 || p.Message != "a b c" {
+		t.Error("invalid uri", p)
+	}
+
+	for _, p := range []*PaymentRequest{
+		{Address: n.Address58(cfg)},
+		{Address: a.Address58(aklib.TestConfig)},
+		{Address: adr, Amount: aklib.ADKSupply + 1},
+		{Address: ""},
+	} {
+		if _, err := p.URI(cfg); err == nil {
+			t.Error("should be error", p)
+		}
+	}
+	for _, uri := range []string{
+		"bitcoin:" + adr,
+		"aidos:" + a.Address58(aklib.TestConfig),
+		"aidos:" + n.Address58(cfg),
+		"aidos:" + adr + "?amount=1.123456789",
+		"aidos:" + adr + "?amount=1e3",
+		"aidos:" + adr + "?amount=-1",
+		"aidos:" + adr + "?amount=1&amount=2",
+		"aidos:" + adr + "?req-foo=bar",
+		"aidos:" + MultisigAddress(aklib.TestConfig, 1, a.Address(aklib.TestConfig)),
+		"aidos:" + adr + "?message=%zz",
+	} {
+		if _, err := ParseURI(cfg, uri); err == nil {
+			t.Error("should be error", uri)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aklib
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+//ADKDecimals is the number of decimal places of ADK.
+const ADKDecimals = 8
+
+//FormatADK formats an amount in unit of transactions as a decimal string in ADK,
+//e.g. 150000000 -> "1.5".
+func FormatADK(v uint64) string {
+	s := strconv.FormatUint(v/ADK, 10)
+	frac := v % ADK
+	if frac == 0 {
+		return s
+	}
+	f := strconv.FormatUint(frac+ADK, 10)[1:]
+	return s + "." + strings.TrimRight(f, "0")
+}
+
+//ParseADK parses a decimal string in ADK like "1.5" and returns the amount
+//in unit of transactions without rounding.
+//It returns an error if the string has more than 8 decimal places or
+//the amount is over ADKSupply.
+func ParseADK(s string) (uint64, error) {
+	ip, fp := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		ip, fp = s[:i], s[i+1:]
+		if fp == "" {
+			return 0, errors.New("no digits after the decimal point")
+		}
+	}
+	if ip == "" {
+		return 0, errors.New("no digits before the decimal point")
+	}
+	if len(fp) > ADKDecimals {
+		return 0, errors.New("too many decimal places")
+	}
+	for _, str := range []string{ip, fp} {
+		for _, c := range str {
+			if c < '0' || c > '9' {
+				return 0, errors.New("invalid character in amount")
+			}
+		}
+	}
+	i, err := strconv.ParseUint(ip, 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	if i > ADKSupply/ADK {
+		return 0, errors.New("amount is over the total supply")
+	}
+	var f uint64
+	if fp != "" {
+		fp += strings.Repeat("0", ADKDecimals-len(fp))
+		f, err = strconv.ParseUint(fp, 10, 64)
+		if err != nil {
+			return 0, err
+		}
+	}
+	v := i*ADK + f
+	if v > ADKSupply {
+		return 0, errors.New("amount is over the total supply")
+	}
+	return v, nil
+}
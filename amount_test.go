@@ -0,0 +1,67 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aklib
+
+import "testing"
+
+func TestADK(t *testing.T) {
+	for s, v := range map[string]uint64{
+		"0":          0,
+		"1":          ADK,
+		"1.5":        150000000,
+		"0.00000001": 1,
+		"0.1":        10000000,
+		"12.3456789": 1234567890,
+		"25000000":   ADKSupply,
+	} {
+		v2, err := ParseADK(s)
+		if err != nil {
+			t.Error(err)
+		}
+		if v2 != v {
+			t.Error("invalid ParseADK", s, v2)
+		}
+		if FormatADK(v) != s {
+			t.Error("invalid FormatADK", v, FormatADK(v))
+		}
+	}
+	for s, v := range map[string]uint64{
+		"01.50": 150000000,
+		"0.10":  10000000,
+		"1.0":   ADK,
+	} {
+		v2, err := ParseADK(s)
+		if err != nil {
+			t.Error(err)
+		}
+		if v2 != v {
+			t.Error("invalid ParseADK", s, v2)
+		}
+	}
+	for _, s := range []string{
+		"", ".", "1.", ".5", "-1", "+1", "1e8", "0.000000001", "1.2.3",
+		"25000000.00000001", "18446744073709551616", "1,5", " 1",
+	} {
+		if _, err := ParseADK(s); err == nil {
+			t.Error("should be error", s)
+		}
+	}
+}
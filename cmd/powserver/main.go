@@ -0,0 +1,44 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//Command powserver serves PoW for remote wallets, which call
+//Transaction.RemotePoW with the URL of this server, e.g. http://host:14280/pow.
+//It has no authentication, so run it only in trusted networks.
+//
+//	powserver [-addr :14280] [-workers 0]
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"github.com/AidosKuneen/aklib/tx"
+)
+
+func main() {
+	addr := flag.String("addr", ":14280", "address to listen")
+	workers := flag.Int("workers", 0, "number of workers for PoW, or the number of CPUs if 0")
+	flag.Parse()
+
+	http.Handle("/pow", &tx.PoWServer{Workers: *workers})
+	log.Println("listening on", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
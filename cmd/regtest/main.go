@@ -0,0 +1,131 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+//Command regtest creates a Config for a local network and its genesis tx.
+//
+//	regtest [-template debug] [-name regtest] [-config regtest.json] [-genesis genesis.json] [address=ADK ...]
+//
+//Genesis addresses must be in the template network and their amounts in ADK
+//must sum to the total supply. They are paid in the new network,
+//whose prefixes differ from the template. If no address is given, a new mnemonic is
+//generated and the total supply is paid to its first address
+//at m/44/0/0/0.
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/AidosKuneen/aklib"
+	"github.com/AidosKuneen/aklib/address"
+	"github.com/AidosKuneen/aklib/tx"
+)
+
+func main() {
+	template := flag.String("template", "debug", "name of the template network or path to its config file")
+	name := flag.String("name", "regtest", "name of the new network")
+	config := flag.String("config", "regtest.json", "path to write the config")
+	genesis := flag.String("genesis", "genesis.json", "path to write the genesis tx")
+	flag.Parse()
+
+	if err := run(*template, *name, *config, *genesis, flag.Args()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func run(template, name, config, genesis string, args []string) error {
+	tmpl := aklib.ConfigByName(template)
+	if tmpl == nil {
+		var err error
+		tmpl, err = aklib.LoadConfigFile(template)
+		if err != nil {
+			return err
+		}
+	}
+	amounts := make(map[string]uint64, len(args))
+	for _, arg := range args {
+		kv := strings.SplitN(arg, "=", 2)
+		if len(kv) != 2 {
+			return fmt.Errorf("invalid argument %s, must be address=ADK", arg)
+		}
+		v, err := aklib.ParseADK(kv[1])
+		if err != nil {
+			return fmt.Errorf("invalid amount %s: %v", kv[1], err)
+		}
+		if _, ok := amounts[kv[0]]; ok {
+			return errors.New("duplicated address " + kv[0])
+		}
+		amounts[kv[0]] = v
+	}
+	var mk []byte
+	path := address.NewPath(0, address.ChainReceive, 0)
+	if len(amounts) == 0 {
+		m := address.GenerateMnemonic()
+		var err error
+		mk, err = address.MnemonicToMasterKey(m, nil)
+		if err != nil {
+			return err
+		}
+		adr, err := path.Address58(tmpl, mk)
+		if err != nil {
+			return err
+		}
+		fmt.Println("mnemonic:", m)
+		amounts[adr] = aklib.ADKSupply
+	}
+
+	cfg, gen, err := tx.Regtest(tmpl, name, amounts)
+	if err != nil {
+		return err
+	}
+	if mk != nil {
+		adr, err := path.Address58(cfg, mk)
+		if err != nil {
+			return err
+		}
+		fmt.Println("address:", adr)
+	}
+	f, err := os.Create(config)
+	if err != nil {
+		return err
+	}
+	if err = cfg.Save(f); err != nil {
+		f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	dat, err := json.MarshalIndent(gen, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err = ioutil.WriteFile(genesis, dat, 0644); err != nil {
+		return err
+	}
+	fmt.Println("genesis tx:", gen.Hash())
+	return nil
+}
@@ -33,8 +33,8 @@ const (
 
 //SRV is a param for SRVLookup
 type SRV struct {
-	Service string
-	Name    string
+	Service string `json:"service"`
+	Name    string `json:"name"`
 }
 
 //DBConfig is a set of config for db.
@@ -0,0 +1,245 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aklib
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+//configValidator is an additional check of Configs set by SetConfigValidator.
+var configValidator func(*Config) error
+
+//SetConfigValidator sets f as an additional check of Configs
+//in LoadConfig and RegisterConfig.
+//The address package sets address.ValidateConfig when imported,
+//which checks base58 strings of prefixes and genesis addresses
+//that cannot be checked in this package.
+func SetConfigValidator(f func(*Config) error) {
+	configValidator = f
+}
+
+//validateAll checks c by Validate and the validator set by SetConfigValidator.
+func (c *Config) validateAll() error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	if configValidator != nil {
+		return configValidator(c)
+	}
+	return nil
+}
+
+//configFile is a representation of Config in JSON files,
+//where prefixes are written in hex.
+type configFile struct {
+	Name                string            `json:"name"`
+	Easiness            uint32            `json:"easiness"`
+	TicketEasiness      uint32            `json:"ticket_easiness"`
+	PrefixPriv          string            `json:"prefix_priv"`
+	PrefixAdrs          string            `json:"prefix_adrs"`
+	PrefixMsig          string            `json:"prefix_msig"`
+	PrefixNkey          string            `json:"prefix_nkey"`
+	PrefixNode          string            `json:"prefix_node"`
+	DefaultPort         uint16            `json:"default_port"`
+	DefaultRPCPort      uint16            `json:"default_rpc_port"`
+	DefaultExplorerPort uint16            `json:"default_explorer_port"`
+	DNS                 []SRV             `json:"dns"`
+	MessageMagic        uint32            `json:"message_magic"`
+	Genesis             map[string]uint64 `json:"genesis"`
+}
+
+//LoadConfig reads a Config in JSON from r and validates it
+//in the same way as RegisterConfig.
+//Prefixes are written in hex, e.g. "prefix_adrs": "ab55".
+//Only JSON is supported; convert other formats such as YAML to JSON beforehand.
+func LoadConfig(r io.Reader) (*Config, error) {
+	var f configFile
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&f); err != nil {
+		return nil, err
+	}
+	c := &Config{
+		Name:                f.Name,
+		Easiness:            f.Easiness,
+		TicketEasiness:      f.TicketEasiness,
+		DefaultPort:         f.DefaultPort,
+		DefaultRPCPort:      f.DefaultRPCPort,
+		DefaultExplorerPort: f.DefaultExplorerPort,
+		DNS:                 f.DNS,
+		MessageMagic:        f.MessageMagic,
+		Genesis:             f.Genesis,
+	}
+	for _, p := range []struct {
+		dst  *[]byte
+		src  string
+		name string
+	}{
+		{&c.PrefixPriv, f.PrefixPriv, "prefix_priv"},
+		{&c.PrefixAdrs, f.PrefixAdrs, "prefix_adrs"},
+		{&c.PrefixMsig, f.PrefixMsig, "prefix_msig"},
+		{&c.PrefixNkey, f.PrefixNkey, "prefix_nkey"},
+		{&c.PrefixNode, f.PrefixNode, "prefix_node"},
+	} {
+		b, err := hex.DecodeString(p.src)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %v", p.name, err)
+		}
+		*p.dst = b
+	}
+	if err := c.validateAll(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+//LoadConfigFile reads a Config in JSON from the file and validates it.
+func LoadConfigFile(fname string) (*Config, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return LoadConfig(f)
+}
+
+//Save writes the Config in JSON to w in the format LoadConfig reads.
+func (c *Config) Save(w io.Writer) error {
+	f := configFile{
+		Name:                c.Name,
+		Easiness:            c.Easiness,
+		TicketEasiness:      c.TicketEasiness,
+		PrefixPriv:          hex.EncodeToString(c.PrefixPriv),
+		PrefixAdrs:          hex.EncodeToString(c.PrefixAdrs),
+		PrefixMsig:          hex.EncodeToString(c.PrefixMsig),
+		PrefixNkey:          hex.EncodeToString(c.PrefixNkey),
+		PrefixNode:          hex.EncodeToString(c.PrefixNode),
+		DefaultPort:         c.DefaultPort,
+		DefaultRPCPort:      c.DefaultRPCPort,
+		DefaultExplorerPort: c.DefaultExplorerPort,
+		DNS:                 c.DNS,
+		MessageMagic:        c.MessageMagic,
+		Genesis:             c.Genesis,
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(&f)
+}
+
+func (c *Config) prefixes() [][]byte {
+	return [][]byte{c.PrefixPriv, c.PrefixAdrs, c.PrefixMsig, c.PrefixNkey, c.PrefixNode}
+}
+
+//Validate checks parameters in the Config.
+//Each prefix must be 2 bytes and unique in the Config, and
+//genesis amounts must sum to ADKSupply.
+//Base58 strings and genesis addresses are checked by address.ValidateConfig,
+//which LoadConfig and RegisterConfig also call if the address package is imported.
+func (c *Config) Validate() error {
+	if c.Name == "" {
+		return errors.New("name must not be empty")
+	}
+	ps := c.prefixes()
+	for i, p := range ps {
+		if len(p) != 2 {
+			return fmt.Errorf("prefix %d must be 2 bytes", i)
+		}
+		if p[0] == 0 {
+			return fmt.Errorf("prefix %d must not start with 0", i)
+		}
+		for j := i + 1; j < len(ps); j++ {
+			if bytes.Equal(p, ps[j]) {
+				return fmt.Errorf("prefix %d is same as %d", i, j)
+			}
+		}
+	}
+	if c.Easiness == 0 || c.TicketEasiness == 0 {
+		return errors.New("easiness must not be 0")
+	}
+	if c.TicketEasiness > c.Easiness {
+		return errors.New("ticket easiness must be less than or equal to easiness")
+	}
+	if c.DefaultPort == 0 || c.DefaultRPCPort == 0 || c.DefaultExplorerPort == 0 {
+		return errors.New("ports must not be 0")
+	}
+	if c.DefaultPort == c.DefaultRPCPort || c.DefaultPort == c.DefaultExplorerPort ||
+		c.DefaultRPCPort == c.DefaultExplorerPort {
+		return errors.New("ports must be different from each other")
+	}
+	if c.MessageMagic == 0 {
+		return errors.New("message magic must not be 0")
+	}
+	var total uint64
+	for adr, v := range c.Genesis {
+		if v == 0 {
+			return fmt.Errorf("genesis amount for %s must not be 0", adr)
+		}
+		if total+v < total {
+			return errors.New("genesis amounts overflow")
+		}
+		total += v
+	}
+	if total != ADKSupply {
+		return fmt.Errorf("total of genesis amounts must be %d, but %d", ADKSupply, total)
+	}
+	return nil
+}
+
+//RegisterConfig validates c by Validate and the validator set by
+//SetConfigValidator, and adds it to Configs.
+//The name and prefixes must not be used in registered Configs.
+//It is not safe to call this while other goroutines read Configs.
+func RegisterConfig(c *Config) error {
+	if err := c.validateAll(); err != nil {
+		return err
+	}
+	for _, r := range Configs {
+		if r.Name == c.Name {
+			return fmt.Errorf("config %s is already registered", c.Name)
+		}
+		for _, p := range c.prefixes() {
+			for _, q := range r.prefixes() {
+				if bytes.Equal(p, q) {
+					return fmt.Errorf("prefix %x is already used in %s", p, r.Name)
+				}
+			}
+		}
+	}
+	Configs = append(Configs, c)
+	return nil
+}
+
+//ConfigByName returns the Config whose name is name in Configs,
+//or nil if not found.
+func ConfigByName(name string) *Config {
+	for _, c := range Configs {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package aklib
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const regtestJSON = `{
+  "name": "regtest",
+  "easiness": 4294967295,
+  "ticket_easiness": 4294967295,
+  "prefix_priv": "1d5c",
+  "prefix_adrs": "abda",
+  "prefix_msig": "6835",
+  "prefix_nkey": "2075",
+  "prefix_node": "5ab5",
+  "default_port": 14470,
+  "default_rpc_port": 14471,
+  "default_explorer_port": 8082,
+  "dns": null,
+  "message_magic": 1234567,
+  "genesis": {
+    "AKADRSRURUqW31YF8PUN6JYQYxgxCXdFR4iAxPTCzbcG2FRZ9wjCQEPgx": 500000000000000,
+    "AKADRSRUV7R2U89HXi2fJp44soDp85V1aFvL7bEtuEoVASgdrvTYECXMg": 2000000000000000
+  }
+}
+`
+
+func TestConfigValidate(t *testing.T) {
+	for _, c := range Configs {
+		if err := c.Validate(); err != nil {
+			t.Error(c.Name, err)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+	c, err := LoadConfig(strings.NewReader(regtestJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	adr := "AKADRSRURUqW31YF8PUN6JYQYxgxCXdFR4iAxPTCzbcG2FRZ9wjCQEPgx"
+	if c.Name != "regtest" || !bytes.Equal(c.PrefixAdrs, []byte{0xab, 0xda}) ||
+		c.Genesis[adr] != 5000000*ADK || c.DefaultRPCPort != 14471 {
+		t.Error("invalid config", c)
+	}
+	var buf bytes.Buffer
+	if err = c.Save(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != regtestJSON {
+		t.Error("invalid json", buf.String())
+	}
+
+	if err = RegisterConfig(c); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		Configs = Configs[:len(Configs)-1]
+	}()
+	if ConfigByName("regtest") != c {
+		t.Error("should be registered")
+	}
+	if ConfigByName("unknown") != nil {
+		t.Error("should be nil")
+	}
+	if err = RegisterConfig(c); err == nil {
+		t.Error("should be error")
+	}
+	c2 := *c
+	c2.Name = "regtest2"
+	if err = RegisterConfig(&c2); err == nil {
+		t.Error("should be error")
+	}
+}
+
+func TestLoadConfigInvalid(t *testing.T) {
+	for _, r := range [][]string{
+		{`"name": "regtest"`, `"name": ""`},
+		{`"easiness": 4294967295`, `"easiness": 0`},
+		{`"easiness": 4294967295`, `"easiness": 1`},
+		{`"prefix_priv": "1d5c"`, `"prefix_priv": "1d5"`},
+		{`"prefix_priv": "1d5c"`, `"prefix_priv": "1d5c00"`},
+		{`"prefix_priv": "1d5c"`, `"prefix_priv": "005c"`},
+		{`"prefix_priv": "1d5c"`, `"prefix_priv": "abda"`},
+		{`"default_port": 14470`, `"default_port": 0`},
+		{`"default_port": 14470`, `"default_port": 14471`},
+		{`"message_magic": 1234567`, `"message_magic": 0`},
+		{`2000000000000000`, `2000000000000001`},
+		{`500000000000000`, `0`},
+		{`2000000000000000`, `18446744073709551615`},
+		{`"dns": null`, `"dns": null, "unknown": 1`},
+	} {
+		s := strings.Replace(regtestJSON, r[0], r[1], 1)
+		if _, err := LoadConfig(strings.NewReader(s)); err == nil {
+			t.Error("should be error", r[1])
+		}
+	}
+}
+
+func TestConfigValidator(t *testing.T) {
+	defer SetConfigValidator(nil)
+	SetConfigValidator(func(c *Config) error {
+		return errors.New("invalid")
+	})
+	c, err := LoadConfig(strings.NewReader(regtestJSON))
+	if err == nil {
+		t.Error("should be error")
+	}
+	SetConfigValidator(nil)
+	c, err = LoadConfig(strings.NewReader(regtestJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetConfigValidator(func(c *Config) error {
+		return errors.New("invalid")
+	})
+	if err = RegisterConfig(c); err == nil {
+		Configs = Configs[:len(Configs)-1]
+		t.Error("should be error")
+	}
+}
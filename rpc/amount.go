@@ -0,0 +1,136 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import (
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+//Amount is an amount of ADK in unit of transactions (1/aklib.ADK ADK).
+//It is encoded in JSON as a number in ADK with at most 8 decimal places,
+//e.g. Amount(150000000) <-> 1.5.
+//Amounts in RPCs can be negative, e.g. for sending in listtransactions.
+type Amount int64
+
+//ParseAmount parses a decimal string in ADK like "1.5", "-0.1" or "1e-8"
+//without rounding.
+//It returns an error if the amount has a fraction under 1/aklib.ADK ADK
+//or is over aklib.ADKSupply.
+func ParseAmount(s string) (Amount, error) {
+	neg := strings.HasPrefix(s, "-")
+	if neg {
+		s = s[1:]
+	}
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(s[i+1:])
+		if err != nil {
+			return 0, errors.New("invalid exponent in amount")
+		}
+		if exp > 20 || exp < -20 {
+			return 0, errors.New("exponent in amount is out of range")
+		}
+		s = shiftPoint(s[:i], exp)
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 && len(s)-i-1 > aklib.ADKDecimals {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	v, err := aklib.ParseADK(s)
+	if err != nil {
+		return 0, err
+	}
+	if neg {
+		return -Amount(v), nil
+	}
+	return Amount(v), nil
+}
+
+//shiftPoint moves the decimal point in s by exp digits to the right.
+func shiftPoint(s string, exp int) string {
+	ip, fp := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		ip, fp = s[:i], s[i+1:]
+	}
+	if ip == "" || (strings.IndexByte(s, '.') >= 0 && fp == "") {
+		//leave it to be rejected by ParseADK.
+		return s
+	}
+	digits := ip + fp
+	pos := len(ip) + exp
+	switch {
+	case pos <= 0:
+		return "0." + strings.Repeat("0", -pos) + digits
+	case pos >= len(digits):
+		return digits + strings.Repeat("0", pos-len(digits))
+	default:
+		return digits[:pos] + "." + digits[pos:]
+	}
+}
+
+//AmountFromFloat converts f in ADK to Amount by rounding to the nearest unit.
+//It is only for compatibility with float64 amounts.
+func AmountFromFloat(f float64) (Amount, error) {
+	if math.IsNaN(f) || math.Abs(f) > float64(aklib.ADKSupply/aklib.ADK) {
+		return 0, errors.New("amount is out of range")
+	}
+	return Amount(math.Round(f * aklib.ADK)), nil
+}
+
+//Float64 returns the amount in ADK as a float64, which may be rounded.
+//It is only for compatibility with float64 amounts.
+func (a Amount) Float64() float64 {
+	return float64(a) / aklib.ADK
+}
+
+//String returns the amount in ADK, e.g. "1.5" or "-0.1".
+func (a Amount) String() string {
+	if a < 0 {
+		return "-" + aklib.FormatADK(uint64(-a))
+	}
+	return aklib.FormatADK(uint64(a))
+}
+
+//MarshalJSON encodes the amount as a JSON number in ADK.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(a.String()), nil
+}
+
+//UnmarshalJSON decodes a JSON number or string in ADK without rounding.
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	v, err := ParseAmount(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
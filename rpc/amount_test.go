@@ -0,0 +1,109 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+func TestAmount(t *testing.T) {
+	for s, v := range map[string]Amount{
+		"0":          0,
+		"1.5":        150000000,
+		"-0.1":       -10000000,
+		"0.00000001": 1,
+		"-25000000":  -Amount(aklib.ADKSupply),
+	} {
+		v2, err := ParseAmount(s)
+		if err != nil {
+			t.Error(err)
+		}
+		if v2 != v {
+			t.Error("invalid ParseAmount", s, v2)
+		}
+		if v.String() != s {
+			t.Error("invalid String", v, v.String())
+		}
+	}
+	for s, v := range map[string]Amount{
+		"1e-8":          1,
+		"1.5E+2":        15000000000,
+		"-15e-1":        -150000000,
+		"0.30000000000": 30000000,
+		"100e-10":       1,
+		"0.000000010e1": 10,
+	} {
+		v2, err := ParseAmount(s)
+		if err != nil {
+			t.Error(err, s)
+		}
+		if v2 != v {
+			t.Error("invalid ParseAmount", s, v2)
+		}
+	}
+	for _, s := range []string{
+		"", "-", "--1", "0.30000000000000004", "1e-9", "1e", "1.e2", "e2",
+		"1e100", "25000000.00000001",
+	} {
+		if _, err := ParseAmount(s); err == nil {
+			t.Error("should be error", s)
+		}
+	}
+}
+
+func TestAmountJSON(t *testing.T) {
+	var v struct {
+		A []Amount          `json:"a"`
+		M map[string]Amount `json:"m"`
+	}
+	in := `{"a":[1.5,-0.00000001,"0.1",1e-8,null],"m":{"x":0.7}}`
+	if err := json.Unmarshal([]byte(in), &v); err != nil {
+		t.Fatal(err)
+	}
+	if len(v.A) != 5 || v.A[0] != 150000000 || v.A[1] != -1 || v.A[2] != 10000000 ||
+		v.A[3] != 1 || v.A[4] != 0 || v.M["x"] != 70000000 {
+		t.Error("invalid amounts", v)
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(out) != `{"a":[1.5,-0.00000001,0.1,0.00000001,0],"m":{"x":0.7}}` {
+		t.Error("invalid json", string(out))
+	}
+	if err := json.Unmarshal([]byte(`[0.123456789]`), &v.A); err == nil {
+		t.Error("should be error")
+	}
+
+	f, err := AmountFromFloat(1 - 0.3)
+	if err != nil {
+		t.Error(err)
+	}
+	if f != 70000000 || f.Float64() != 0.7 {
+		t.Error("invalid AmountFromFloat", f)
+	}
+	if _, err := AmountFromFloat(1e10); err == nil {
+		t.Error("should be error")
+	}
+}
@@ -0,0 +1,255 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+
+	"github.com/AidosKuneen/aklib/tx"
+)
+
+//DefaultMaxBatch is the default max number of RPCs in a batch request.
+const DefaultMaxBatch = 100
+
+//Call is an RPC queued in a Batch.
+type Call struct {
+	Method string
+	ID     uint64
+	Err    error //error of the RPC, set by Batch.Send
+	params interface{}
+	result interface{}
+	after  func() error
+}
+
+//Batch queues RPCs and sends them in JSON-RPC batch requests.
+//Each request has at most MaxBatch RPCs of the client.
+type Batch struct {
+	client *RPC
+	calls  []*Call
+}
+
+//NewBatch returns an empty Batch.
+func (client *RPC) NewBatch() *Batch {
+	return &Batch{
+		client: client,
+	}
+}
+
+//Len returns the number of queued RPCs.
+func (b *Batch) Len() int {
+	return len(b.calls)
+}
+
+//Add queues an RPC. result must be a pointer and is filled by Send
+//if the RPC succeeded.
+func (b *Batch) Add(method string, params interface{}, result interface{}) *Call {
+	c := &Call{
+		Method: method,
+		ID:     atomic.AddUint64(&b.client.lastID, 1),
+		params: params,
+		result: result,
+	}
+	b.calls = append(b.calls, c)
+	return c
+}
+
+//RawTxCall is a getrawtx RPC in a Batch.
+type RawTxCall struct {
+	*Call
+	Result *tx.Transaction
+}
+
+//GetRawTx queues a getrawtx RPC.
+func (b *Batch) GetRawTx(txid string) *RawTxCall {
+	var dat []byte
+	c := &RawTxCall{}
+	c.Call = b.Add("getrawtx", []interface{}{txid}, &dat)
+	c.after = func() error {
+		var err error
+		c.Result, err = unmarshalTx(dat)
+		return err
+	}
+	return c
+}
+
+//LastHistoryCall is a getlasthistory RPC in a Batch.
+type LastHistoryCall struct {
+	*Call
+	Result []*tx.InoutHash
+}
+
+//GetLastHistory queues a getlasthistory RPC.
+func (b *Batch) GetLastHistory(adr string) *LastHistoryCall {
+	var hs []*InoutHash
+	c := &LastHistoryCall{}
+	c.Call = b.Add("getlasthistory", []interface{}{adr}, &hs)
+	c.after = func() error {
+		var err error
+		c.Result, err = toInoutHashes(hs)
+		return err
+	}
+	return c
+}
+
+//TxsStatusCall is a gettxsstatus RPC in a Batch.
+type TxsStatusCall struct {
+	*Call
+	Result []*TxStatus
+}
+
+//GetTxsStatus queues a gettxsstatus RPC.
+func (b *Batch) GetTxsStatus(txid ...string) *TxsStatusCall {
+	arg := make([]interface{}, len(txid))
+	for i := range txid {
+		arg[i] = txid[i]
+	}
+	c := &TxsStatusCall{}
+	c.Call = b.Add("gettxsstatus", arg, &c.Result)
+	return c
+}
+
+//Send sends all queued RPCs and empties the queue.
+//Errors of each RPC are set to Err of its Call.
+//It returns an error if any RPC failed.
+func (b *Batch) Send(ctx context.Context) error {
+	max := b.client.MaxBatch
+	if max <= 0 {
+		max = DefaultMaxBatch
+	}
+	calls := b.calls
+	b.calls = nil
+	var failed int
+	for len(calls) > 0 {
+		n := max
+		if n > len(calls) {
+			n = len(calls)
+		}
+		b.client.send(ctx, calls[:n])
+		for _, c := range calls[:n] {
+			if c.Err != nil {
+				failed++
+			}
+		}
+		calls = calls[n:]
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d RPCs in the batch failed", failed)
+	}
+	return nil
+}
+
+func setError(calls []*Call, err error) {
+	for _, c := range calls {
+		c.Err = err
+	}
+}
+
+func (client *RPC) send(ctx context.Context, calls []*Call) {
+	reqs := make([]*Request, len(calls))
+	for i, c := range calls {
+		params, err := json.Marshal(c.params)
+		if err != nil {
+			setError(calls, err)
+			return
+		}
+		reqs[i] = &Request{
+			JSONRPC: "1.0",
+			ID:      c.ID,
+			Method:  c.Method,
+			Params:  params,
+		}
+	}
+	ctx, cancel := client.withTimeout(ctx)
+	defer cancel()
+	resp, bs, err := client.post(ctx, "batch", reqs)
+	if err != nil {
+		setError(calls, err)
+		return
+	}
+	var rs []*struct {
+		Result json.RawMessage `json:"result"`
+		Error  *Err            `json:"error"`
+		ID     json.RawMessage `json:"id"`
+	}
+	if err = json.Unmarshal(bs, &rs); err != nil {
+		//the node may return one error for the whole batch.
+		var r Response
+		switch {
+		case json.Unmarshal(bs, &r) == nil && r.Error != nil:
+			setError(calls, r.Error.toError("batch"))
+		case resp.StatusCode != http.StatusOK:
+			setError(calls, &HTTPError{
+				Method:     "batch",
+				StatusCode: resp.StatusCode,
+				Status:     resp.Status,
+			})
+		default:
+			setError(calls, &TransportError{
+				Method: "batch",
+				Err:    fmt.Errorf("invalid response: %w", err),
+			})
+		}
+		return
+	}
+	//responses may have ids which are not numbers, e.g. null for invalid requests.
+	ids := make(map[string]*Call, len(calls))
+	for _, c := range calls {
+		id, err := json.Marshal(c.ID)
+		if err != nil {
+			setError(calls, err)
+			return
+		}
+		ids[string(id)] = c
+		c.Err = &TransportError{
+			Method: c.Method,
+			Err:    errors.New("no response in the batch"),
+		}
+	}
+	for _, r := range rs {
+		c, ok := ids[string(r.ID)]
+		if !ok {
+			continue
+		}
+		delete(ids, string(r.ID))
+		switch {
+		case r.Error != nil:
+			c.Err = r.Error.toError(c.Method)
+		case c.result == nil:
+			c.Err = nil
+		default:
+			c.Err = nil
+			if err := json.Unmarshal(r.Result, c.result); err != nil {
+				c.Err = &TransportError{
+					Method: c.Method,
+					Err:    fmt.Errorf("invalid response: %w", err),
+				}
+			}
+		}
+		if c.Err == nil && c.after != nil {
+			c.Err = c.after()
+		}
+	}
+}
@@ -0,0 +1,218 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/AidosKuneen/aklib"
+	"github.com/AidosKuneen/aklib/arypack"
+	"github.com/AidosKuneen/aklib/tx"
+)
+
+type testBatchResponse struct {
+	Result interface{} `json:"result"`
+	Error  *Err        `json:"error"`
+	ID     interface{} `json:"id"`
+}
+
+func batchServer(t *testing.T, nreq *int32) *httptest.Server {
+	tr := tx.New(aklib.DebugConfig)
+	tr.Message = []byte("batch")
+	dat := arypack.Marshal(tr)
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(nreq, 1)
+		var reqs []*Request
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Error(err)
+		}
+		ids := make(map[float64]struct{})
+		res := make([]*testBatchResponse, 0, len(reqs))
+		for i := len(reqs) - 1; i >= 0; i-- {
+			req := reqs[i]
+			id := req.ID.(float64)
+			if _, ok := ids[id]; ok {
+				t.Error("duplicated id", id)
+			}
+			ids[id] = struct{}{}
+			var params []string
+			if err := json.Unmarshal(req.Params, &params); err != nil {
+				t.Error(err)
+			}
+			r := &testBatchResponse{
+				ID: req.ID,
+			}
+			switch {
+			case len(params) > 0 && params[0] == "skip":
+				//a response whose id is not a number.
+				res = append(res, &testBatchResponse{
+					ID: "skip",
+					Error: &Err{
+						Code:    CodeInvalidRequest,
+						Message: "invalid request",
+					},
+				})
+				continue
+			case len(params) > 0 && params[0] == "locked":
+				r.Error = &Err{
+					Code:    CodeWalletUnlockNeeded,
+					Message: "wallet is locked",
+				}
+			case req.Method == "getrawtx":
+				r.Result = dat
+			case req.Method == "getlasthistory":
+				r.Result = []*InoutHash{
+					{
+						Hash:  "00ff",
+						Type:  tx.TypeOut,
+						Index: 3,
+					},
+				}
+			case req.Method == "gettxsstatus":
+				ss := make([]*TxStatus, len(params))
+				for i, p := range params {
+					ss[i] = &TxStatus{
+						Hash:   p,
+						Exists: true,
+					}
+				}
+				r.Result = ss
+			default:
+				r.Error = &Err{
+					Code:    CodeMethodNotFound,
+					Message: "method not found",
+				}
+			}
+			res = append(res, r)
+		}
+		if err := json.NewEncoder(w).Encode(res); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func TestBatch(t *testing.T) {
+	var nreq int32
+	s := batchServer(t, &nreq)
+	defer s.Close()
+	client := New(s.URL, "", "", nil)
+	client.MaxBatch = 2
+	b := client.NewBatch()
+	raw := b.GetRawTx("aa")
+	his := b.GetLastHistory("adr")
+	st := b.GetTxsStatus("bb", "cc")
+	locked := b.GetLastHistory("locked")
+	skip := b.GetRawTx("skip")
+	var out []string
+	unknown := b.Add("unknown", nil, &out)
+	if b.Len() != 6 {
+		t.Error("invalid length", b.Len())
+	}
+	err := b.Send(context.Background())
+	if err == nil {
+		t.Error("should be error")
+	}
+	if n := atomic.LoadInt32(&nreq); n != 3 {
+		t.Error("invalid number of requests", n)
+	}
+	if b.Len() != 0 {
+		t.Error("should be empty", b.Len())
+	}
+
+	if raw.Err != nil {
+		t.Error(raw.Err)
+	}
+	if string(raw.Result.Message) != "batch" {
+		t.Error("invalid tx", raw.Result)
+	}
+	if his.Err != nil {
+		t.Error(his.Err)
+	}
+	if len(his.Result) != 1 || his.Result[0].Index != 3 ||
+		his.Result[0].Type != tx.TypeOut || his.Result[0].Hash[1] != 0xff {
+		t.Error("invalid history", his.Result)
+	}
+	if st.Err != nil {
+		t.Error(st.Err)
+	}
+	if len(st.Result) != 2 || st.Result[0].Hash != "bb" || st.Result[1].Hash != "cc" {
+		t.Error("invalid status", st.Result)
+	}
+	if !IsWalletLocked(locked.Err) || locked.Result != nil {
+		t.Error("should be locked", locked.Err)
+	}
+	var terr *TransportError
+	if !errors.As(skip.Err, &terr) || terr.Method != "getrawtx" {
+		t.Error("should be no response", skip.Err)
+	}
+	if !IsMethodNotFound(unknown.Err) {
+		t.Error("should be method not found", unknown.Err)
+	}
+
+	if err = b.Send(context.Background()); err != nil {
+		t.Error(err)
+	}
+	if n := atomic.LoadInt32(&nreq); n != 3 {
+		t.Error("invalid number of requests", n)
+	}
+}
+
+func TestBatchError(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "auth") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if _, err := w.Write([]byte(`{"result":null,"error":{"code":-32600,"message":"batch is not supported"},"id":null}`)); err != nil {
+			t.Error(err)
+		}
+	}))
+	defer s.Close()
+	client := New(s.URL, "", "", nil)
+	b := client.NewBatch()
+	c1 := b.GetRawTx("aa")
+	c2 := b.GetRawTx("bb")
+	if err := b.Send(context.Background()); err == nil {
+		t.Error("should be error")
+	}
+	if c, _ := ErrorCode(c1.Err); c != CodeInvalidRequest {
+		t.Error("invalid error", c1.Err)
+	}
+	if c, _ := ErrorCode(c2.Err); c != CodeInvalidRequest {
+		t.Error("invalid error", c2.Err)
+	}
+
+	client.Endpoint = s.URL + "/auth"
+	c1 = b.GetRawTx("aa")
+	if err := b.Send(context.Background()); err == nil {
+		t.Error("should be error")
+	}
+	if !errors.Is(c1.Err, ErrUnauthorized) {
+		t.Error("should be unauthorized", c1.Err)
+	}
+}
@@ -22,12 +22,15 @@ package rpc
 
 import (
 	"bytes"
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"log"
 	"net/http"
+	"time"
 
 	"github.com/AidosKuneen/aklib/arypack"
 	"github.com/AidosKuneen/aklib/tx"
@@ -39,7 +42,7 @@ type Addr struct {
 	Service byte   `json:"service"`
 }
 
-func (client *RPC) request(method string, params interface{}, out interface{}) error {
+func (client *RPC) request(ctx context.Context, method string, params interface{}, out interface{}) error {
 	req := &Request{
 		JSONRPC: "1.0",
 		ID:      "aklib",
@@ -50,15 +53,27 @@ func (client *RPC) request(method string, params interface{}, out interface{}) e
 	if err != nil {
 		return err
 	}
-	return client.do(req, out)
+	ctx, cancel := client.withTimeout(ctx)
+	defer cancel()
+	return client.do(ctx, method, req, out)
+}
+
+func (client *RPC) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if client.Timeout > 0 {
+		return context.WithTimeout(ctx, client.Timeout)
+	}
+	return context.WithCancel(ctx)
 }
 
 //RPC is for calling RPCs.
 type RPC struct {
+	lastID   uint64 //must be 64-bit aligned for atomic
 	client   *http.Client
 	Endpoint string
 	User     string
 	Password string
+	Timeout  time.Duration //timeout for each RPC, no timeout if 0
+	MaxBatch int           //max number of RPCs in a batch request, DefaultMaxBatch if 0
 }
 
 //New takes an (optional) endpoint and optional http.Client and returns
@@ -75,15 +90,16 @@ func New(endpoint, user, password string, c *http.Client) *RPC {
 	}
 }
 
-func (client *RPC) do(cmd interface{}, out interface{}) error {
+//post sends cmd in JSON and returns the response with its body.
+func (client *RPC) post(ctx context.Context, method string, cmd interface{}) (*http.Response, []byte, error) {
 	b, err := json.Marshal(cmd)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	rd := bytes.NewReader(b)
-	req, err := http.NewRequest("POST", client.Endpoint, rd)
+	req, err := http.NewRequestWithContext(ctx, "POST", client.Endpoint, rd)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	if client.User != "" || client.Password != "" {
 		req.SetBasicAuth(client.User, client.Password)
@@ -91,7 +107,7 @@ func (client *RPC) do(cmd interface{}, out interface{}) error {
 	}
 	resp, err := client.client.Do(req)
 	if err != nil {
-		return err
+		return nil, nil, &TransportError{Method: method, Err: err}
 	}
 	defer func() {
 		if err = resp.Body.Close(); err != nil {
@@ -100,63 +116,113 @@ func (client *RPC) do(cmd interface{}, out interface{}) error {
 	}()
 
 	bs, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, &TransportError{Method: method, Err: err}
+	}
+	return resp, bs, nil
+}
+
+func (client *RPC) do(ctx context.Context, method string, cmd interface{}, out interface{}) error {
+	resp, bs, err := client.post(ctx, method, cmd)
 	if err != nil {
 		return err
 	}
 	var r Response
-	if err := json.Unmarshal(bs, &r); err != nil {
+	err = json.Unmarshal(bs, &r)
+	if err == nil && r.Error != nil {
+		return r.Error.toError(method)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return &HTTPError{
+			Method:     method,
+			StatusCode: resp.StatusCode,
+			Status:     resp.Status,
+		}
+	}
+	if err != nil {
 		log.Println(string(bs))
-		return err
+		return &TransportError{
+			Method: method,
+			Err:    fmt.Errorf("invalid response: %w", err),
+		}
 	}
-	if r.Error != nil {
-		return errors.New(r.Error.Message)
+	if err := json.Unmarshal(bs, out); err != nil {
+		return &TransportError{
+			Method: method,
+			Err:    fmt.Errorf("invalid response: %w", err),
+		}
 	}
-	return json.Unmarshal(bs, out)
+	return nil
 }
 
 //SetTxFee sends a settxfee RPC.
-func (client *RPC) SetTxFee(amount float64) (bool, error) {
+func (client *RPC) SetTxFee(amount Amount) (bool, error) {
+	return client.SetTxFeeContext(context.Background(), amount)
+}
+
+//SetTxFeeContext sends a settxfee RPC, with ctx.
+func (client *RPC) SetTxFeeContext(ctx context.Context, amount Amount) (bool, error) {
 	var out struct {
 		Result bool `json:"result"`
 	}
-	err := client.request("settxfee", []float64{amount}, &out)
+	err := client.request(ctx, "settxfee", []Amount{amount}, &out)
 	return out.Result, err
 }
 
 //GetNewAddress sends a getnewaddress RPC.
 func (client *RPC) GetNewAddress(account string) (string, error) {
+	return client.GetNewAddressContext(context.Background(), account)
+}
+
+//GetNewAddressContext sends a getnewaddress RPC, with ctx.
+func (client *RPC) GetNewAddressContext(ctx context.Context, account string) (string, error) {
 	var out struct {
 		Result string `json:"result"`
 	}
-	err := client.request("getnewaddress", []string{account}, &out)
+	err := client.request(ctx, "getnewaddress", []string{account}, &out)
 	return out.Result, err
 }
 
 //WalletPassphrase sends a walletpassphrase RPC.
 func (client *RPC) WalletPassphrase(pp string, sec int) error {
+	return client.WalletPassphraseContext(context.Background(), pp, sec)
+}
+
+//WalletPassphraseContext sends a walletpassphrase RPC, with ctx.
+func (client *RPC) WalletPassphraseContext(ctx context.Context, pp string, sec int) error {
 	ary := make([]interface{}, 0, 2)
 	ary = append(ary, pp)
 	ary = append(ary, sec)
 	var out struct {
 		Result interface{} `json:"result"`
 	}
-	return client.request("walletpassphrase", ary, &out)
+	return client.request(ctx, "walletpassphrase", ary, &out)
 }
 
 //SendMany sends a sendmany RPC.
-func (client *RPC) SendMany(account string, amounts map[string]float64) (string, error) {
+func (client *RPC) SendMany(account string, amounts map[string]Amount) (string, error) {
+	return client.SendManyContext(context.Background(), account, amounts)
+}
+
+//SendManyContext sends a sendmany RPC, with ctx.
+func (client *RPC) SendManyContext(ctx context.Context, account string, amounts map[string]Amount) (string, error) {
 	ary := make([]interface{}, 0, 2)
 	ary = append(ary, account)
 	ary = append(ary, amounts)
 	var out struct {
 		Result string `json:"result"`
 	}
-	err := client.request("sendmany", ary, &out)
+	err := client.request(ctx, "sendmany", ary, &out)
 	return out.Result, err
 }
 
 //ListTransactions sends a listtransactions RPC.
 func (client *RPC) ListTransactions(account string, count, skip int) ([]*Transaction, error) {
+	return client.ListTransactionsContext(context.Background(), account, count, skip)
+}
+
+//ListTransactionsContext sends a listtransactions RPC, with ctx.
+func (client *RPC) ListTransactionsContext(ctx context.Context, account string, count, skip int) ([]*Transaction, error) {
 	ary := make([]interface{}, 0, 2)
 	ary = append(ary, account)
 	ary = append(ary, count)
@@ -164,174 +230,285 @@ func (client *RPC) ListTransactions(account string, count, skip int) ([]*Transac
 	var out struct {
 		Result []*Transaction `json:"result"`
 	}
-	err := client.request("listtransactions", ary, &out)
+	err := client.request(ctx, "listtransactions", ary, &out)
 	return out.Result, err
 }
 
 //ValidateAddress sends a validateaddress RPC.
 func (client *RPC) ValidateAddress(address string) (*Info, error) {
+	return client.ValidateAddressContext(context.Background(), address)
+}
+
+//ValidateAddressContext sends a validateaddress RPC, with ctx.
+func (client *RPC) ValidateAddressContext(ctx context.Context, address string) (*Info, error) {
 	var out struct {
 		Result *Info `json:"result"`
 	}
-	err := client.request("validateaddress", []string{address}, &out)
+	err := client.request(ctx, "validateaddress", []string{address}, &out)
 	return out.Result, err
 }
 
 //GetBalance sends a getbalance RPC.
-func (client *RPC) GetBalance(account string) (float64, error) {
+func (client *RPC) GetBalance(account string) (Amount, error) {
+	return client.GetBalanceContext(context.Background(), account)
+}
+
+//GetBalanceContext sends a getbalance RPC, with ctx.
+func (client *RPC) GetBalanceContext(ctx context.Context, account string) (Amount, error) {
 	var out struct {
-		Result float64 `json:"result"`
+		Result Amount `json:"result"`
 	}
-	err := client.request("getbalance", []string{account}, &out)
+	err := client.request(ctx, "getbalance", []string{account}, &out)
 	return out.Result, err
 }
 
 //GetTransaction sends a gettransactions RPC.
 func (client *RPC) GetTransaction(txid string) (*Gettx, error) {
+	return client.GetTransactionContext(context.Background(), txid)
+}
+
+//GetTransactionContext sends a gettransactions RPC, with ctx.
+func (client *RPC) GetTransactionContext(ctx context.Context, txid string) (*Gettx, error) {
 	var out struct {
 		Result *Gettx `json:"result"`
 	}
-	err := client.request("gettransaction", []string{txid}, &out)
+	err := client.request(ctx, "gettransaction", []string{txid}, &out)
 	return out.Result, err
 }
 
 //ListPeer sends a listpeer RPC.
 func (client *RPC) ListPeer() ([]Addr, error) {
+	return client.ListPeerContext(context.Background())
+}
+
+//ListPeerContext sends a listpeer RPC, with ctx.
+func (client *RPC) ListPeerContext(ctx context.Context) ([]Addr, error) {
 	var out struct {
 		Result []Addr `json:"result"`
 	}
-	err := client.request("listpeer", nil, &out)
+	err := client.request(ctx, "listpeer", nil, &out)
 	return out.Result, err
 }
 
 //DumpPrivKey sends a dumpprivkey RPC.
 func (client *RPC) DumpPrivKey() (string, error) {
+	return client.DumpPrivKeyContext(context.Background())
+}
+
+//DumpPrivKeyContext sends a dumpprivkey RPC, with ctx.
+func (client *RPC) DumpPrivKeyContext(ctx context.Context) (string, error) {
 	var out struct {
 		Result string `json:"result"`
 	}
-	err := client.request("dumpprivkey", nil, &out)
+	err := client.request(ctx, "dumpprivkey", nil, &out)
 	return out.Result, err
 }
 
 //ListBanned sends a listbanned RPC.
 func (client *RPC) ListBanned() ([]*Bans, error) {
+	return client.ListBannedContext(context.Background())
+}
+
+//ListBannedContext sends a listbanned RPC, with ctx.
+func (client *RPC) ListBannedContext(ctx context.Context) ([]*Bans, error) {
 	var out struct {
 		Result []*Bans `json:"result"`
 	}
-	err := client.request("listbanned", nil, &out)
+	err := client.request(ctx, "listbanned", nil, &out)
 	return out.Result, err
 }
 
 //Stop sends a stop RPC.
 func (client *RPC) Stop() error {
+	return client.StopContext(context.Background())
+}
+
+//StopContext sends a stop RPC, with ctx.
+func (client *RPC) StopContext(ctx context.Context) error {
 	var out struct {
 		Result string `json:"result"`
 	}
-	return client.request("stop", nil, &out)
+	return client.request(ctx, "stop", nil, &out)
 }
 
 //DumpWallet sends a dumpwallet RPC.
 func (client *RPC) DumpWallet(fname string) error {
+	return client.DumpWalletContext(context.Background(), fname)
+}
+
+//DumpWalletContext sends a dumpwallet RPC, with ctx.
+func (client *RPC) DumpWalletContext(ctx context.Context, fname string) error {
 	var out struct {
 		Result struct{} `json:"result"`
 	}
-	return client.request("dumpwallet", []interface{}{fname}, &out)
+	return client.request(ctx, "dumpwallet", []interface{}{fname}, &out)
 }
 
 //ImportWallet sends a importwallet RPC.
 func (client *RPC) ImportWallet(fname string) error {
+	return client.ImportWalletContext(context.Background(), fname)
+}
+
+//ImportWalletContext sends a importwallet RPC, with ctx.
+func (client *RPC) ImportWalletContext(ctx context.Context, fname string) error {
 	var out struct {
 		Result struct{} `json:"result"`
 	}
-	return client.request("importwallet", []interface{}{fname}, &out)
+	return client.request(ctx, "importwallet", []interface{}{fname}, &out)
 }
 
 //SendRawTX sends a sendrawtx RPC.
 func (client *RPC) SendRawTX(tx *tx.Transaction, typ tx.Type) (string, error) {
+	return client.SendRawTXContext(context.Background(), tx, typ)
+}
+
+//SendRawTXContext sends a sendrawtx RPC, with ctx.
+func (client *RPC) SendRawTXContext(ctx context.Context, tx *tx.Transaction, typ tx.Type) (string, error) {
 	ttx := arypack.Marshal(tx)
 	var out struct {
 		Result string `json:"result"`
 	}
-	return out.Result, client.request("sendrawtx", []interface{}{ttx, typ}, &out)
+	return out.Result, client.request(ctx, "sendrawtx", []interface{}{ttx, typ}, &out)
 }
 
 //GetNodeinfo sends a getnodeinfo RPC.
 func (client *RPC) GetNodeinfo() (*NodeInfo, error) {
+	return client.GetNodeinfoContext(context.Background())
+}
+
+//GetNodeinfoContext sends a getnodeinfo RPC, with ctx.
+func (client *RPC) GetNodeinfoContext(ctx context.Context) (*NodeInfo, error) {
 	var out struct {
 		Result *NodeInfo `json:"result"`
 	}
-	err := client.request("getnodeinfo", []interface{}{}, &out)
+	err := client.request(ctx, "getnodeinfo", []interface{}{}, &out)
 	return out.Result, err
 }
 
 //GetLeaves sends a getleaves RPC.
 func (client *RPC) GetLeaves() ([]string, error) {
+	return client.GetLeavesContext(context.Background())
+}
+
+//GetLeavesContext sends a getleaves RPC, with ctx.
+func (client *RPC) GetLeavesContext(ctx context.Context) ([]string, error) {
 	var out struct {
 		Result []string `json:"result"`
 	}
-	err := client.request("getleaves", []interface{}{}, &out)
+	err := client.request(ctx, "getleaves", []interface{}{}, &out)
 	return out.Result, err
 }
 
 //GetLastHistory sends a getlasthistory RPC.
 func (client *RPC) GetLastHistory(adr string) ([]*tx.InoutHash, error) {
+	return client.GetLastHistoryContext(context.Background(), adr)
+}
+
+//GetLastHistoryContext sends a getlasthistory RPC, with ctx.
+func (client *RPC) GetLastHistoryContext(ctx context.Context, adr string) ([]*tx.InoutHash, error) {
 	var out struct {
 		Result []*InoutHash `json:"result"`
 	}
-	err := client.request("getlasthistory", []interface{}{adr}, &out)
-	r := make([]*tx.InoutHash, len(out.Result))
-	for i, or := range out.Result {
+	err := client.request(ctx, "getlasthistory", []interface{}{adr}, &out)
+	if err != nil {
+		return nil, err
+	}
+	return toInoutHashes(out.Result)
+}
+
+func toInoutHashes(hs []*InoutHash) ([]*tx.InoutHash, error) {
+	r := make([]*tx.InoutHash, len(hs))
+	for i, or := range hs {
 		r[i] = &tx.InoutHash{
 			Type:  or.Type,
 			Index: or.Index,
 		}
+		var err error
 		r[i].Hash, err = hex.DecodeString(or.Hash)
 		if err != nil {
 			return nil, err
 		}
 	}
-	return r, err
+	return r, nil
+}
+
+//IsUsed returns true if adr has histories, by sending a getlasthistory RPC.
+//RPC implements address.Lookup by this.
+func (client *RPC) IsUsed(adr string) (bool, error) {
+	return client.IsUsedContext(context.Background(), adr)
+}
+
+//IsUsedContext returns true if adr has histories, by sending a getlasthistory RPC
+//with ctx.
+func (client *RPC) IsUsedContext(ctx context.Context, adr string) (bool, error) {
+	hs, err := client.GetLastHistoryContext(ctx, adr)
+	if err != nil {
+		return false, err
+	}
+	return len(hs) > 0, nil
 }
 
 //GetRawTx sends a getrawtx RPC.
 func (client *RPC) GetRawTx(txid string) (*tx.Transaction, error) {
+	return client.GetRawTxContext(context.Background(), txid)
+}
+
+//GetRawTxContext sends a getrawtx RPC, with ctx.
+func (client *RPC) GetRawTxContext(ctx context.Context, txid string) (*tx.Transaction, error) {
 	var out struct {
 		Result []byte `json:"result"`
 	}
-	err := client.request("getrawtx", []interface{}{txid}, &out)
+	err := client.request(ctx, "getrawtx", []interface{}{txid}, &out)
 	if err != nil {
 		return nil, err
 	}
+	return unmarshalTx(out.Result)
+}
+
+func unmarshalTx(dat []byte) (*tx.Transaction, error) {
 	var tr tx.Transaction
-	err = arypack.Unmarshal(out.Result, &tr)
+	err := arypack.Unmarshal(dat, &tr)
 	return &tr, err
 }
 
-func (client *RPC) getMinableTx(arg interface{}) (*tx.Transaction, error) {
+func (client *RPC) getMinableTx(ctx context.Context, arg interface{}) (*tx.Transaction, error) {
 	var out struct {
 		Result []byte `json:"result"`
 	}
-	err := client.request("getminabletx", []interface{}{arg}, &out)
+	err := client.request(ctx, "getminabletx", []interface{}{arg}, &out)
 	if err != nil {
 		return nil, err
 	}
-	var tr tx.Transaction
-	err = arypack.Unmarshal(out.Result, &tr)
-	return &tr, err
+	return unmarshalTx(out.Result)
 }
 
 //GetMinableTicketTx sends a getminabletx RPC for tiecket reward.
 func (client *RPC) GetMinableTicketTx() (*tx.Transaction, error) {
-	return client.getMinableTx("ticket")
+	return client.GetMinableTicketTxContext(context.Background())
+}
+
+//GetMinableTicketTxContext sends a getminabletx RPC for tiecket reward, with ctx.
+func (client *RPC) GetMinableTicketTxContext(ctx context.Context) (*tx.Transaction, error) {
+	return client.getMinableTx(ctx, "ticket")
 }
 
 //GetMinableFeeTx sends a getminabletx RPC for fee reward.
-func (client *RPC) GetMinableFeeTx(fee float64) (*tx.Transaction, error) {
-	return client.getMinableTx(fee)
+func (client *RPC) GetMinableFeeTx(fee Amount) (*tx.Transaction, error) {
+	return client.GetMinableFeeTxContext(context.Background(), fee)
+}
+
+//GetMinableFeeTxContext sends a getminabletx RPC for fee reward, with ctx.
+func (client *RPC) GetMinableFeeTxContext(ctx context.Context, fee Amount) (*tx.Transaction, error) {
+	return client.getMinableTx(ctx, fee)
 }
 
 //GetTxsStatus sends a gettxsstatus RPC.
 func (client *RPC) GetTxsStatus(txid ...string) ([]*TxStatus, error) {
+	return client.GetTxsStatusContext(context.Background(), txid...)
+}
+
+//GetTxsStatusContext sends a gettxsstatus RPC, with ctx.
+func (client *RPC) GetTxsStatusContext(ctx context.Context, txid ...string) ([]*TxStatus, error) {
 	var out struct {
 		Result []*TxStatus `json:"result"`
 	}
@@ -339,7 +516,7 @@ func (client *RPC) GetTxsStatus(txid ...string) ([]*TxStatus, error) {
 	for i := range txid {
 		arg[i] = txid[i]
 	}
-	err := client.request("gettxsstatus", arg, &out)
+	err := client.request(ctx, "gettxsstatus", arg, &out)
 	if err != nil {
 		return nil, err
 	}
@@ -348,14 +525,24 @@ func (client *RPC) GetTxsStatus(txid ...string) ([]*TxStatus, error) {
 
 //WalletLock sends a walletlock RPC.
 func (client *RPC) WalletLock() error {
+	return client.WalletLockContext(context.Background())
+}
+
+//WalletLockContext sends a walletlock RPC, with ctx.
+func (client *RPC) WalletLockContext(ctx context.Context) error {
 	var out struct {
 		Result struct{} `json:"result"`
 	}
-	return client.request("walletlock", []interface{}{}, &out)
+	return client.request(ctx, "walletlock", []interface{}{}, &out)
 }
 
 //SendFrom sends a sendfrom RPC.
-func (client *RPC) SendFrom(account, address string, amount float64) (string, error) {
+func (client *RPC) SendFrom(account, address string, amount Amount) (string, error) {
+	return client.SendFromContext(context.Background(), account, address, amount)
+}
+
+//SendFromContext sends a sendfrom RPC, with ctx.
+func (client *RPC) SendFromContext(ctx context.Context, account, address string, amount Amount) (string, error) {
 	ary := make([]interface{}, 0, 3)
 	ary = append(ary, account)
 	ary = append(ary, address)
@@ -363,35 +550,45 @@ func (client *RPC) SendFrom(account, address string, amount float64) (string, er
 	var out struct {
 		Result string `json:"result"`
 	}
-	err := client.request("sendfrom", ary, &out)
+	err := client.request(ctx, "sendfrom", ary, &out)
 	return out.Result, err
 }
 
 //SendToAddress sends a sendtoaddress RPC.
-func (client *RPC) SendToAddress(address string, amount float64) (string, error) {
+func (client *RPC) SendToAddress(address string, amount Amount) (string, error) {
+	return client.SendToAddressContext(context.Background(), address, amount)
+}
+
+//SendToAddressContext sends a sendtoaddress RPC, with ctx.
+func (client *RPC) SendToAddressContext(ctx context.Context, address string, amount Amount) (string, error) {
 	ary := make([]interface{}, 0, 2)
 	ary = append(ary, address)
 	ary = append(ary, amount)
 	var out struct {
 		Result string `json:"result"`
 	}
-	err := client.request("sendtoaddress", ary, &out)
+	err := client.request(ctx, "sendtoaddress", ary, &out)
 	return out.Result, err
 }
 
 //AddressGroup is a result of ListAddressGroupings.
 type AddressGroup struct {
 	Address string
-	Amount  float64
+	Amount  Amount
 	Account *string
 }
 
 //ListAddressGroupings sends a listaddressgroupings RPC.
 func (client *RPC) ListAddressGroupings() ([]*AddressGroup, error) {
+	return client.ListAddressGroupingsContext(context.Background())
+}
+
+//ListAddressGroupingsContext sends a listaddressgroupings RPC, with ctx.
+func (client *RPC) ListAddressGroupingsContext(ctx context.Context) ([]*AddressGroup, error) {
 	var out struct {
-		Result [][][]interface{} `json:"result"`
+		Result [][][]json.RawMessage `json:"result"`
 	}
-	err := client.request("listaddressgroupings", nil, &out)
+	err := client.request(ctx, "listaddressgroupings", nil, &out)
 	if err != nil {
 		return nil, err
 	}
@@ -404,18 +601,15 @@ func (client *RPC) ListAddressGroupings() ([]*AddressGroup, error) {
 			return nil, errors.New("invalid length of result")
 		}
 		a := &AddressGroup{}
-		var ok bool
-		a.Address, ok = o[0].(string)
-		if !ok {
+		if err := json.Unmarshal(o[0], &a.Address); err != nil {
 			return nil, errors.New("#1 of result must be string")
 		}
-		a.Amount, ok = o[1].(float64)
-		if !ok {
-			return nil, errors.New("#2 of result must be float64")
+		if err := json.Unmarshal(o[1], &a.Amount); err != nil {
+			return nil, errors.New("#2 of result must be amount")
 		}
 		if len(o) == 3 {
-			acc, ok := o[2].(string)
-			if !ok {
+			var acc string
+			if err := json.Unmarshal(o[2], &acc); err != nil {
 				return nil, errors.New("#3 of result must be string")
 			}
 			a.Account = &acc
@@ -426,38 +620,58 @@ func (client *RPC) ListAddressGroupings() ([]*AddressGroup, error) {
 }
 
 //ListAccounts sends a listaccounts RPC.
-func (client *RPC) ListAccounts() (map[string]float64, error) {
+func (client *RPC) ListAccounts() (map[string]Amount, error) {
+	return client.ListAccountsContext(context.Background())
+}
+
+//ListAccountsContext sends a listaccounts RPC, with ctx.
+func (client *RPC) ListAccountsContext(ctx context.Context) (map[string]Amount, error) {
 	var out struct {
-		Result map[string]float64 `json:"result"`
+		Result map[string]Amount `json:"result"`
 	}
-	err := client.request("listaccounts", nil, &out)
+	err := client.request(ctx, "listaccounts", nil, &out)
 	return out.Result, err
 }
 
 //GetAccount sends a getaccount RPC.
 func (client *RPC) GetAccount(account string) (string, error) {
+	return client.GetAccountContext(context.Background(), account)
+}
+
+//GetAccountContext sends a getaccount RPC, with ctx.
+func (client *RPC) GetAccountContext(ctx context.Context, account string) (string, error) {
 	var out struct {
 		Result string `json:"result"`
 	}
-	err := client.request("getaccount", []interface{}{account}, &out)
+	err := client.request(ctx, "getaccount", []interface{}{account}, &out)
 	return out.Result, err
 }
 
 //GetMultisigInfo sends a getmultisiginfo RPC.
 func (client *RPC) GetMultisigInfo(adr string) (*tx.MultisigStruct, error) {
+	return client.GetMultisigInfoContext(context.Background(), adr)
+}
+
+//GetMultisigInfoContext sends a getmultisiginfo RPC, with ctx.
+func (client *RPC) GetMultisigInfoContext(ctx context.Context, adr string) (*tx.MultisigStruct, error) {
 	var out struct {
 		Result tx.MultisigStruct `json:"result"`
 	}
-	err := client.request("getmultisiginfo", []string{adr}, &out)
+	err := client.request(ctx, "getmultisiginfo", []string{adr}, &out)
 	return &out.Result, err
 }
 
 //GetLedger sends a getledger RPC.
 func (client *RPC) GetLedger(txid string) (*Ledger, error) {
+	return client.GetLedgerContext(context.Background(), txid)
+}
+
+//GetLedgerContext sends a getledger RPC, with ctx.
+func (client *RPC) GetLedgerContext(ctx context.Context, txid string) (*Ledger, error) {
 	var out struct {
 		Result *Ledger `json:"result"`
 	}
-	err := client.request("getledger", []interface{}{txid}, &out)
+	err := client.request(ctx, "getledger", []interface{}{txid}, &out)
 	if err != nil {
 		return nil, err
 	}
@@ -251,13 +251,13 @@ func TestRPCClient(t *testing.T) {
 
 	ipport := fmt.Sprintf("http://%s:%d", s.RPCBind, s.RPCPort)
 	cl := rpcc.New(ipport, "hoehoe", s.RPCPassword, nil)
-	_, err := cl.SetTxFee(0.01)
+	_, err := cl.SetTxFee(aklib.ADK / 100)
 	if err == nil {
 		t.Error("should be error")
 	}
 	time.Sleep(3 * time.Second)
 	cl = rpcc.New(ipport, s.RPCUser, s.RPCPassword, nil)
-	_, err = cl.SetTxFee(0.01)
+	_, err = cl.SetTxFee(aklib.ADK / 100)
 	if err != nil {
 		t.Error(err)
 	}
@@ -299,7 +299,7 @@ func TestRPCClient(t *testing.T) {
 	if err2 != nil {
 		t.Error(err2)
 	}
-	if bal != 1 {
+	if bal != aklib.ADK {
 		t.Fatal("invlid balance", bal)
 	}
 
@@ -308,8 +308,8 @@ func TestRPCClient(t *testing.T) {
 		t.Error(err)
 	}
 
-	id, err = cl.SendMany("", map[string]float64{
-		b.Address58(s.Config): 0.1,
+	id, err = cl.SendMany("", map[string]rpcc.Amount{
+		b.Address58(s.Config): aklib.ADK / 10,
 	})
 	if err != nil {
 		t.Fatal(err)
@@ -340,14 +340,14 @@ func TestRPCClient(t *testing.T) {
 	if err != nil {
 		t.Error(err)
 	}
-	if bal != 1-0.1 {
+	if bal != aklib.ADK*9/10 {
 		t.Fatal("invlid balance", bal)
 	}
 	gettx, err2 := cl.GetTransaction(id)
 	if err2 != nil {
 		t.Error(err2)
 	}
-	if gettx.Amount != -0.1 {
+	if gettx.Amount != -aklib.ADK/10 {
 		t.Error("invalid amount")
 	}
 	if gettx.Confirmations != 100000 {
@@ -452,7 +452,7 @@ func TestRPCClient(t *testing.T) {
 		t.Error(err)
 	}
 	time.Sleep(2 * time.Second)
-	fe, err := cl.GetMinableFeeTx(10.0 / aklib.ADK)
+	fe, err := cl.GetMinableFeeTx(10)
 	if err != nil {
 		t.Error(err)
 	}
@@ -504,7 +504,7 @@ func TestRPCClient(t *testing.T) {
 		t.Error("invalid tx stat")
 	}
 
-	sfid, err := cl.SendFrom("", b.Address58(s.Config), 0.1)
+	sfid, err := cl.SendFrom("", b.Address58(s.Config), aklib.ADK/10)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -515,7 +515,7 @@ func TestRPCClient(t *testing.T) {
 	confirmAll(t, true)
 	time.Sleep(2 * time.Second)
 
-	id, err = cl.SendToAddress(b.Address58(s.Config), 0.1)
+	id, err = cl.SendToAddress(b.Address58(s.Config), aklib.ADK/10)
 	if err != nil {
 		t.Error(err)
 	}
@@ -551,7 +551,7 @@ func TestRPCClient(t *testing.T) {
 			if ac.Account != nil {
 				t.Error("invalid account")
 			}
-			if ac.Amount == 1-0.3 {
+			if ac.Amount == aklib.ADK*7/10 {
 				namt++
 			} else {
 				if ac.Amount != 0 {
@@ -577,7 +577,7 @@ func TestRPCClient(t *testing.T) {
 	if !ok {
 		t.Error("invalid account")
 	}
-	if a != 1-0.3 {
+	if a != aklib.ADK*7/10 {
 		t.Error("invliad amount")
 	}
 	acc, err := cl.GetAccount(adr)
@@ -601,7 +601,7 @@ func TestRPCClient(t *testing.T) {
 	if !strings.HasPrefix(trs[0].Address, "AKADR") {
 		t.Error("invalid address")
 	}
-	if trs[0].Amount != 0.8 {
+	if trs[0].Amount != aklib.ADK*8/10 {
 		t.Error("invalid amount", trs[0].Amount)
 	}
 	if trs[0].Confirmations != 100000 {
@@ -0,0 +1,195 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+)
+
+//Error codes in JSON-RPC responses.
+const (
+	//standard JSON-RPC 2.0 errors
+	CodeParseError     int64 = -32700
+	CodeInvalidRequest int64 = -32600
+	CodeMethodNotFound int64 = -32601
+	CodeInvalidParams  int64 = -32602
+	CodeInternalError  int64 = -32603
+
+	//general errors, compatible with bitcoind
+	CodeMisc                 int64 = -1
+	CodeType                 int64 = -3
+	CodeInvalidAddress       int64 = -5
+	CodeOutOfMemory          int64 = -7
+	CodeInvalidParameter     int64 = -8
+	CodeDatabase             int64 = -20
+	CodeDeserialization      int64 = -22
+	CodeVerify               int64 = -25
+	CodeVerifyRejected       int64 = -26
+	CodeVerifyAlreadyInChain int64 = -27
+	CodeInWarmup             int64 = -28
+
+	//wallet errors, compatible with bitcoind
+	CodeWallet                    int64 = -4
+	CodeWalletInsufficientFunds   int64 = -6
+	CodeWalletInvalidAccountName  int64 = -11
+	CodeWalletKeypoolRanOut       int64 = -12
+	CodeWalletUnlockNeeded        int64 = -13
+	CodeWalletPassphraseIncorrect int64 = -14
+	CodeWalletWrongEncState       int64 = -15
+	CodeWalletEncryptionFailed    int64 = -16
+	CodeWalletAlreadyUnlocked     int64 = -17
+)
+
+//RPCError is an error returned from the node in a JSON-RPC response.
+type RPCError struct {
+	Code    int64
+	Message string
+	Method  string
+}
+
+func (e *RPCError) Error() string {
+	return fmt.Sprintf("rpc %s: %s (code %d)", e.Method, e.Message, e.Code)
+}
+
+func (e *Err) toError(method string) error {
+	return &RPCError{
+		Code:    e.Code,
+		Message: e.Message,
+		Method:  method,
+	}
+}
+
+//ErrorCode returns the code of the RPCError in err.
+//It returns false if err doesn't have any RPCError.
+func ErrorCode(err error) (int64, bool) {
+	var e *RPCError
+	if !errors.As(err, &e) {
+		return 0, false
+	}
+	return e.Code, true
+}
+
+func hasCode(err error, code int64) bool {
+	c, ok := ErrorCode(err)
+	return ok && c == code
+}
+
+//IsWalletLocked returns true if err is caused by the locked wallet.
+func IsWalletLocked(err error) bool {
+	return hasCode(err, CodeWalletUnlockNeeded)
+}
+
+//IsInsufficientFunds returns true if err is caused by insufficient funds.
+func IsInsufficientFunds(err error) bool {
+	return hasCode(err, CodeWalletInsufficientFunds)
+}
+
+//IsPassphraseIncorrect returns true if err is caused by an incorrect passphrase.
+func IsPassphraseIncorrect(err error) bool {
+	return hasCode(err, CodeWalletPassphraseIncorrect)
+}
+
+//IsMethodNotFound returns true if err is caused by an unknown method.
+func IsMethodNotFound(err error) bool {
+	return hasCode(err, CodeMethodNotFound)
+}
+
+//IsInvalidAddress returns true if err is caused by an invalid address.
+func IsInvalidAddress(err error) bool {
+	return hasCode(err, CodeInvalidAddress)
+}
+
+//IsInvalidParameter returns true if err is caused by invalid params.
+func IsInvalidParameter(err error) bool {
+	return hasCode(err, CodeInvalidParameter) || hasCode(err, CodeInvalidParams)
+}
+
+//Errors for HTTP statuses which are not OK, used as targets of errors.Is.
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrServer       = errors.New("server error")
+	ErrHTTPStatus   = errors.New("unexpected HTTP status")
+)
+
+//HTTPError is an error for a response whose HTTP status is not OK
+//and which doesn't have a JSON-RPC error.
+type HTTPError struct {
+	Method     string
+	StatusCode int
+	Status     string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("rpc %s: %s", e.Method, e.Status)
+}
+
+//Unwrap returns the error for the status, e.g. ErrUnauthorized for 401.
+func (e *HTTPError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusNotFound:
+		return ErrNotFound
+	}
+	if e.StatusCode >= 500 {
+		return ErrServer
+	}
+	return ErrHTTPStatus
+}
+
+//TransportError is an error while sending a request to the node or
+//receiving its response, i.e. the node didn't process the RPC or
+//its response is broken.
+type TransportError struct {
+	Method string
+	Err    error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("rpc %s: %v", e.Method, e.Err)
+}
+
+//Unwrap returns the underlying error.
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+//Timeout returns true if the RPC was timed out.
+func (e *TransportError) Timeout() bool {
+	if errors.Is(e.Err, context.DeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(e.Err, &ne) && ne.Timeout()
+}
+
+//Canceled returns true if the RPC was canceled by the context.
+func (e *TransportError) Canceled() bool {
+	return errors.Is(e.Err, context.Canceled)
+}
@@ -0,0 +1,106 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type testResponse struct {
+	status int
+	body   string
+}
+
+func errorServer(t *testing.T, res map[string]*testResponse) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		tr := res[req.Method]
+		w.WriteHeader(tr.status)
+		if _, err := w.Write([]byte(tr.body)); err != nil {
+			t.Error(err)
+		}
+	}))
+}
+
+func TestRPCError(t *testing.T) {
+	s := errorServer(t, map[string]*testResponse{
+		"sendtoaddress": {http.StatusInternalServerError,
+			`{"result":null,"error":{"code":-13,"message":"wallet is locked"},"id":"aklib"}`},
+		"sendfrom": {http.StatusOK,
+			`{"result":null,"error":{"code":-6,"message":"insufficient funds"},"id":"aklib"}`},
+		"getbalance": {http.StatusNotFound,
+			`{"result":null,"error":{"code":-32601,"message":"method not found"},"id":"aklib"}`},
+		"getnodeinfo": {http.StatusUnauthorized, ""},
+		"getleaves":   {http.StatusBadGateway, "<html></html>"},
+		"stop":        {http.StatusTeapot, ""},
+	})
+	defer s.Close()
+	client := New(s.URL, "", "", nil)
+
+	_, err := client.SendToAddress("", 1)
+	var rerr *RPCError
+	if !errors.As(err, &rerr) || rerr.Code != CodeWalletUnlockNeeded ||
+		rerr.Method != "sendtoaddress" || rerr.Message != "wallet is locked" {
+		t.Error("invalid error", err)
+	}
+	if !IsWalletLocked(err) || IsInsufficientFunds(err) {
+		t.Error("should be wallet locked", err)
+	}
+	_, err = client.SendFrom("", "", 1)
+	if !IsInsufficientFunds(err) || IsWalletLocked(err) {
+		t.Error("should be insufficient funds", err)
+	}
+	_, err = client.GetBalance("")
+	if !IsMethodNotFound(err) {
+		t.Error("should be method not found", err)
+	}
+	if c, ok := ErrorCode(err); !ok || c != CodeMethodNotFound {
+		t.Error("invalid code", c)
+	}
+
+	_, err = client.GetNodeinfo()
+	var herr *HTTPError
+	if !errors.As(err, &herr) || herr.StatusCode != http.StatusUnauthorized ||
+		!errors.Is(err, ErrUnauthorized) {
+		t.Error("should be unauthorized", err)
+	}
+	if _, ok := ErrorCode(err); ok || IsWalletLocked(err) {
+		t.Error("should not be RPCError", err)
+	}
+	_, err = client.GetLeaves()
+	if !errors.Is(err, ErrServer) {
+		t.Error("should be server error", err)
+	}
+	err = client.Stop()
+	if !errors.Is(err, ErrHTTPStatus) || errors.Is(err, ErrServer) {
+		t.Error("should be http error", err)
+	}
+	if IsWalletLocked(nil) {
+		t.Error("should not be locked")
+	}
+}
@@ -143,19 +143,19 @@ type Info struct {
 
 //Details is a struct for gettransaction RPC.
 type Details struct {
-	Account   string  `json:"account"`
-	Address   string  `json:"address"`
-	Category  string  `json:"category"`
-	Amount    float64 `json:"amount"`
-	Vout      int64   `json:"vout"`
-	Fee       float64 `json:"fee"`
-	Abandoned *bool   `json:"abandoned,omitempty"`
+	Account   string `json:"account"`
+	Address   string `json:"address"`
+	Category  string `json:"category"`
+	Amount    Amount `json:"amount"`
+	Vout      int64  `json:"vout"`
+	Fee       Amount `json:"fee"`
+	Abandoned *bool  `json:"abandoned,omitempty"`
 }
 
 //Gettx is a struct for gettransaction RPC.
 type Gettx struct {
-	Amount            float64    `json:"amount"`
-	Fee               float64    `json:"fee"`
+	Amount            Amount     `json:"amount"`
+	Fee               Amount     `json:"fee"`
 	Confirmations     int        `json:"confirmations"`
 	Blockhash         *string    `json:"blockhash,omitempty"`
 	Blockindex        *int64     `json:"blockindex,omitempty"`
@@ -174,12 +174,12 @@ type Transaction struct {
 	Account  *string `json:"account"`
 	Address  string  `json:"address"`
 	Category string  `json:"category"`
-	Amount   float64 `json:"amount"`
+	Amount   Amount  `json:"amount"`
 	// Label             string      `json:"label"`
-	Vout          int64   `json:"vout"`
-	Fee           float64 `json:"fee"`
-	Confirmations int     `json:"confirmations"`
-	Trusted       *bool   `json:"trusted,omitempty"`
+	Vout          int64  `json:"vout"`
+	Fee           Amount `json:"fee"`
+	Confirmations int    `json:"confirmations"`
+	Trusted       *bool  `json:"trusted,omitempty"`
 	// Generated         bool        `json:"generated"`
 	Blockhash       *string  `json:"blockhash,omitempty"`
 	Blockindex      *int64   `json:"blockindex,omitempty"`
@@ -0,0 +1,120 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testServer(t *testing.T, delay time.Duration) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		switch req.Method {
+		case "getbalance":
+			if _, err := w.Write([]byte(`{"result":1.5,"error":null,"id":"aklib"}`)); err != nil {
+				t.Error(err)
+			}
+		case "getleaves":
+			if _, err := w.Write([]byte(`{"result":1.5,"error":null,"id":"aklib"}`)); err != nil {
+				t.Error(err)
+			}
+		default:
+			if _, err := w.Write([]byte(`broken`)); err != nil {
+				t.Error(err)
+			}
+		}
+	}))
+}
+
+func TestContext(t *testing.T) {
+	s := testServer(t, 0)
+	defer s.Close()
+	client := New(s.URL, "", "", nil)
+	b, err := client.GetBalanceContext(context.Background(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b != 150000000 {
+		t.Error("invalid balance", b)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.GetBalanceContext(ctx, "")
+	var terr *TransportError
+	if !errors.As(err, &terr) || !terr.Canceled() || terr.Timeout() ||
+		terr.Method != "getbalance" {
+		t.Error("should be canceled", err)
+	}
+
+	_, err = client.GetNodeinfo()
+	if !errors.As(err, &terr) || terr.Canceled() || terr.Timeout() ||
+		terr.Method != "getnodeinfo" {
+		t.Error("should be transport error", err)
+	}
+
+	//a valid response whose result is of a wrong type.
+	_, err = client.GetLeaves()
+	if !errors.As(err, &terr) || terr.Canceled() || terr.Method != "getleaves" {
+		t.Error("should be transport error", err)
+	}
+}
+
+func TestTimeout(t *testing.T) {
+	s := testServer(t, time.Second)
+	defer s.Close()
+	client := New(s.URL, "", "", nil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := client.GetBalanceContext(ctx, "")
+	var terr *TransportError
+	if !errors.As(err, &terr) || !terr.Timeout() || !errors.Is(err, context.DeadlineExceeded) {
+		t.Error("should be timeout", err)
+	}
+
+	client.Timeout = 10 * time.Millisecond
+	_, err = client.GetBalance("")
+	if !errors.As(err, &terr) || !terr.Timeout() {
+		t.Error("should be timeout", err)
+	}
+
+	s.Close()
+	client.Timeout = 0
+	_, err = client.GetBalance("")
+	if !errors.As(err, &terr) || terr.Timeout() {
+		t.Error("should be transport error", err)
+	}
+}
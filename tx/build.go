@@ -24,7 +24,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"sort"
+	"time"
 
 	"github.com/AidosKuneen/aklib"
 	"github.com/AidosKuneen/aklib/address"
@@ -65,6 +65,14 @@ type Wallet2 interface {
 //Build builds a tx for sending coins.
 func Build(conf *aklib.Config, w Wallet, tag []byte, outputs []*RawOutput,
 	beforeSignFunc func(*Transaction) error) (*Transaction, error) {
+	return build(conf, w, tag, outputs, beforeSignFunc, nil)
+}
+
+func build(conf *aklib.Config, w Wallet, tag []byte, outputs []*RawOutput,
+	beforeSignFunc func(*Transaction) error, sel CoinSelector) (*Transaction, error) {
+	if sel == nil {
+		sel = LargestFirst{}
+	}
 	ls, err := w.GetLeaves()
 	if err != nil {
 		return nil, err
@@ -79,22 +87,17 @@ func Build(conf *aklib.Config, w Wallet, tag []byte, outputs []*RawOutput,
 	if err != nil {
 		return nil, err
 	}
-	sort.Slice(utxos, func(i, j int) bool {
-		return utxos[i].Value < utxos[j].Value
-	})
-	i := sort.Search(len(utxos), func(i int) bool {
-		return utxos[i].Value >= outtotal
-	})
+	selected, err := sel.Select(utxos, outtotal)
+	if err != nil {
+		return nil, err
+	}
 	change := int64(outtotal)
 	var adrs []AddressIF
-	if i == len(utxos) {
-		i--
-	}
-	for ; i >= 0 && change > 0; i-- {
-		log.Println(utxos[i])
-		tr.AddInput(utxos[i].Hash, utxos[i].Index)
-		adrs = append(adrs, utxos[i].Address)
-		change -= int64(utxos[i].Value)
+	for _, u := range selected {
+		log.Println(u)
+		tr.AddInput(u.Hash, u.Index)
+		adrs = append(adrs, u.Address)
+		change -= int64(u.Value)
 	}
 	if change > 0 {
 		return nil, fmt.Errorf("insufficient balance %v", change)
@@ -130,13 +133,16 @@ func Build(conf *aklib.Config, w Wallet, tag []byte, outputs []*RawOutput,
 
 //BuildParam is a param for building a tx..
 type BuildParam struct {
-	Comment string
-	Dest    []*RawOutput
-	PoWType Type
-	Fee     uint64
+	Comment  string
+	Dest     []*RawOutput
+	PoWType  Type
+	Fee      uint64
+	Selector CoinSelector //LargestFirst if nil
+	LockTime time.Time    //the tx is invalid until then if not zero
 }
 
 //Build2 builds a tx for sending coins with fee or ticket..
+//If p.LockTime is set, the tx is signed now but cannot be accepted before p.LockTime.
 func Build2(conf *aklib.Config, w Wallet2, p *BuildParam) (*Transaction, error) {
 	var err error
 	if p.PoWType == TypeRewardFee {
@@ -150,6 +156,9 @@ func Build2(conf *aklib.Config, w Wallet2, p *BuildParam) (*Transaction, error)
 	}
 	var ticketadr *address.Address
 	f := func(tr *Transaction) error {
+		if !p.LockTime.IsZero() {
+			tr.LockTime = p.LockTime.Truncate(time.Second)
+		}
 		switch p.PoWType {
 		case TypeRewardFee:
 			tr.Body.HashType = HashTypeExcludeOutputs | 0x1
@@ -164,7 +173,7 @@ func Build2(conf *aklib.Config, w Wallet2, p *BuildParam) (*Transaction, error)
 		}
 		return nil
 	}
-	tr, err := Build(conf, w, []byte(p.Comment), p.Dest, f)
+	tr, err := build(conf, w, []byte(p.Comment), p.Dest, f, p.Selector)
 	if err != nil {
 		return nil, err
 	}
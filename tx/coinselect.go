@@ -0,0 +1,210 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+
+	akrand "github.com/AidosKuneen/aklib/rand"
+)
+
+//CoinSelector selects UTXOs to be used as inputs of a tx.
+type CoinSelector interface {
+	//Select returns UTXOs from utxos whose total value is target or more.
+	Select(utxos []*UTXO, target uint64) ([]*UTXO, error)
+}
+
+func sortedUTXOs(utxos []*UTXO) []*UTXO {
+	us := make([]*UTXO, len(utxos))
+	copy(us, utxos)
+	sort.SliceStable(us, func(i, j int) bool {
+		return us[i].Value < us[j].Value
+	})
+	return us
+}
+
+func checkBalance(utxos []*UTXO, target uint64) error {
+	var total uint64
+	for _, u := range utxos {
+		total += u.Value
+	}
+	if total < target {
+		return fmt.Errorf("insufficient balance %v", target-total)
+	}
+	return nil
+}
+
+//LargestFirst selects the smallest UTXO which covers the target if any,
+//or selects UTXOs from the largest one.
+type LargestFirst struct{}
+
+//Select selects UTXOs.
+func (LargestFirst) Select(utxos []*UTXO, target uint64) ([]*UTXO, error) {
+	if err := checkBalance(utxos, target); err != nil {
+		return nil, err
+	}
+	us := sortedUTXOs(utxos)
+	i := sort.Search(len(us), func(i int) bool {
+		return us[i].Value >= target
+	})
+	if i == len(us) {
+		i--
+	}
+	var r []*UTXO
+	var total uint64
+	for ; i >= 0 && total < target; i-- {
+		r = append(r, us[i])
+		total += us[i].Value
+	}
+	return r, nil
+}
+
+//SmallestFirst selects UTXOs from the smallest one
+//to consolidate small UTXOs.
+type SmallestFirst struct{}
+
+//Select selects UTXOs.
+func (SmallestFirst) Select(utxos []*UTXO, target uint64) ([]*UTXO, error) {
+	if err := checkBalance(utxos, target); err != nil {
+		return nil, err
+	}
+	us := sortedUTXOs(utxos)
+	var r []*UTXO
+	var total uint64
+	for i := 0; total < target; i++ {
+		r = append(r, us[i])
+		total += us[i].Value
+	}
+	return r, nil
+}
+
+//DefaultBnBTries is the default number of tries in BranchAndBound.
+const DefaultBnBTries = 100000
+
+//BranchAndBound searches UTXOs whose total is exactly the target
+//so that the tx has no change output.
+//If not found, it uses Fallback, or LargestFirst if Fallback is nil.
+type BranchAndBound struct {
+	Tries    int //max number of tries, or DefaultBnBTries if 0
+	Fallback CoinSelector
+}
+
+//Select selects UTXOs.
+func (b *BranchAndBound) Select(utxos []*UTXO, target uint64) ([]*UTXO, error) {
+	if err := checkBalance(utxos, target); err != nil {
+		return nil, err
+	}
+	us := sortedUTXOs(utxos)
+	//descending order
+	for i, j := 0, len(us)-1; i < j; i, j = i+1, j-1 {
+		us[i], us[j] = us[j], us[i]
+	}
+	//rest[i] is the total of us[i:]
+	rest := make([]uint64, len(us)+1)
+	for i := len(us) - 1; i >= 0; i-- {
+		rest[i] = rest[i+1] + us[i].Value
+	}
+	tries := b.Tries
+	if tries <= 0 {
+		tries = DefaultBnBTries
+	}
+	var selected []*UTXO
+	var search func(i int, total uint64) bool
+	search = func(i int, total uint64) bool {
+		if total == target {
+			return true
+		}
+		tries--
+		if tries < 0 || i == len(us) || total+rest[i] < target {
+			return false
+		}
+		if total+us[i].Value <= target {
+			selected = append(selected, us[i])
+			if search(i+1, total+us[i].Value) {
+				return true
+			}
+			selected = selected[:len(selected)-1]
+		}
+		//skip UTXOs with the same value, which would result in the same.
+		j := i + 1
+		for j < len(us) && us[j].Value == us[i].Value {
+			j++
+		}
+		return search(j, total)
+	}
+	if target > 0 && search(0, 0) {
+		return selected, nil
+	}
+	fb := b.Fallback
+	if fb == nil {
+		fb = LargestFirst{}
+	}
+	return fb.Select(utxos, target)
+}
+
+//RandomImprove selects UTXOs randomly until the target is covered,
+//and then adds random UTXOs while the total gets closer to twice the target
+//without exceeding three times, so that the change is similar to the payment.
+type RandomImprove struct {
+	Rand *rand.Rand //source of randomness, or a cryptographic one if nil
+}
+
+//Select selects UTXOs.
+func (ri *RandomImprove) Select(utxos []*UTXO, target uint64) ([]*UTXO, error) {
+	if err := checkBalance(utxos, target); err != nil {
+		return nil, err
+	}
+	r := ri.Rand
+	if r == nil {
+		r = akrand.R
+	}
+	us := make([]*UTXO, len(utxos))
+	copy(us, utxos)
+	r.Shuffle(len(us), func(i, j int) {
+		us[i], us[j] = us[j], us[i]
+	})
+	var selected []*UTXO
+	var total uint64
+	i := 0
+	for ; total < target; i++ {
+		selected = append(selected, us[i])
+		total += us[i].Value
+	}
+	ideal := 2 * target
+	for ; i < len(us); i++ {
+		t := total + us[i].Value
+		if t > 3*target || distance(t, ideal) >= distance(total, ideal) {
+			continue
+		}
+		selected = append(selected, us[i])
+		total = t
+	}
+	return selected, nil
+}
+
+func distance(a, b uint64) uint64 {
+	if a > b {
+		return a - b
+	}
+	return b - a
+}
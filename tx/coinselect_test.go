@@ -0,0 +1,203 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"errors"
+	"math/rand"
+	"testing"
+
+	"github.com/AidosKuneen/aklib"
+	"github.com/AidosKuneen/aklib/address"
+)
+
+type testAddress struct {
+	*address.Address
+}
+
+func (a *testAddress) Sign(tr *Transaction) error {
+	return tr.Sign(a.Address)
+}
+
+func (a *testAddress) String() string {
+	return a.Address58(aklib.DebugConfig)
+}
+
+type testWallet struct {
+	utxos    []*UTXO
+	change   *address.Address
+	noTicket bool
+}
+
+func (w *testWallet) GetUTXO(uint64) ([]*UTXO, error) {
+	return w.utxos, nil
+}
+
+func (w *testWallet) NewChangeAddress() (*address.Address, error) {
+	return w.change, nil
+}
+
+func (w *testWallet) GetLeaves() ([]Hash, error) {
+	return []Hash{one}, nil
+}
+
+func (w *testWallet) GetTicketout() (Hash, *address.Address, error) {
+	if w.noTicket {
+		return nil, nil, errors.New("no ticket")
+	}
+	return two, a[4], nil
+}
+
+//testUTXOs returns UTXOs with values in order of a[0], a[1],...
+func testUTXOs(values ...uint64) []*UTXO {
+	us := make([]*UTXO, len(values))
+	for i, v := range values {
+		us[i] = &UTXO{
+			Address: &testAddress{a[i%len(a)]},
+			InoutHash: &InoutHash{
+				Hash:  zero,
+				Type:  TypeOut,
+				Index: byte(i),
+			},
+			Value: v,
+		}
+	}
+	return us
+}
+
+func values(us []*UTXO) []uint64 {
+	vs := make([]uint64, len(us))
+	for i, u := range us {
+		vs[i] = u.Value
+	}
+	return vs
+}
+
+func equalValues(us []*UTXO, vs ...uint64) bool {
+	if len(us) != len(vs) {
+		return false
+	}
+	for i, u := range us {
+		if u.Value != vs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestCoinSelector(t *testing.T) {
+	us := testUTXOs(8, 1, 21, 3, 13, 2, 5)
+	for _, c := range []struct {
+		sel    CoinSelector
+		target uint64
+		want   []uint64
+	}{
+		{LargestFirst{}, 4, []uint64{5}},
+		{LargestFirst{}, 40, []uint64{21, 13, 8}},
+		{LargestFirst{}, 0, nil},
+		{SmallestFirst{}, 4, []uint64{1, 2, 3}},
+		{SmallestFirst{}, 21, []uint64{1, 2, 3, 5, 8, 13}},
+		{&BranchAndBound{}, 4, []uint64{3, 1}},
+		{&BranchAndBound{}, 40, []uint64{21, 13, 5, 1}},
+		{&BranchAndBound{}, 53, []uint64{21, 13, 8, 5, 3, 2, 1}},
+		{&BranchAndBound{Tries: 1}, 40, []uint64{21, 13, 8}},
+		{&BranchAndBound{Tries: 1, Fallback: SmallestFirst{}}, 20, []uint64{1, 2, 3, 5, 8, 13}},
+	} {
+		r, err := c.sel.Select(us, c.target)
+		if err != nil {
+			t.Error(err)
+		}
+		if !equalValues(r, c.want...) {
+			t.Error("invalid selection", c.sel, c.target, values(r))
+		}
+	}
+	r, err := (&BranchAndBound{}).Select(testUTXOs(5, 10), 7)
+	if err != nil {
+		t.Error(err)
+	}
+	if !equalValues(r, 10) {
+		t.Error("invalid selection", values(r))
+	}
+
+	r, err = (&RandomImprove{Rand: rand.New(rand.NewSource(1))}).Select(us, 10)
+	if err != nil {
+		t.Error(err)
+	}
+	r2, err := (&RandomImprove{Rand: rand.New(rand.NewSource(1))}).Select(us, 10)
+	if err != nil {
+		t.Error(err)
+	}
+	if !equalValues(r2, values(r)...) {
+		t.Error("should be same with the same seed", values(r), values(r2))
+	}
+	var total uint64
+	used := make(map[*UTXO]bool)
+	for _, u := range r {
+		if used[u] {
+			t.Error("duplicated")
+		}
+		used[u] = true
+		total += u.Value
+	}
+	if total < 10 {
+		t.Error("invalid total", values(r))
+	}
+
+	for _, sel := range []CoinSelector{LargestFirst{}, SmallestFirst{}, &BranchAndBound{}, &RandomImprove{}} {
+		_, err := sel.Select(us, 54)
+		if err == nil || err.Error() != "insufficient balance 1" {
+			t.Error("should be error", sel, err)
+		}
+	}
+}
+
+func TestBuildSelector(t *testing.T) {
+	cfg := aklib.DebugConfig
+	w := &testWallet{
+		utxos:  testUTXOs(8, 1, 21, 3, 13, 2, 5),
+		change: a[4],
+	}
+	tr, err := Build2(cfg, w, &BuildParam{
+		Dest: []*RawOutput{
+			{Address: a[0].Address58(cfg), Value: 40},
+		},
+		PoWType: TypeNormal,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.Inputs) != 3 || len(tr.Outputs) != 2 || tr.Outputs[0].Value != 2 {
+		t.Error("invalid tx", tr.Inputs, tr.Outputs)
+	}
+	tr, err = Build2(cfg, w, &BuildParam{
+		Dest: []*RawOutput{
+			{Address: a[0].Address58(cfg), Value: 40},
+		},
+		PoWType:  TypeNormal,
+		Selector: &BranchAndBound{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.Inputs) != 4 || len(tr.Outputs) != 1 || len(tr.Signatures) != 3 {
+		t.Error("invalid tx", tr.Inputs, tr.Outputs)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"fmt"
+)
+
+//Category is a category of errors in checking txs.
+//It can be used as a target of errors.Is, e.g. errors.Is(err, tx.CategoryPoW).
+type Category byte
+
+//Categories of errors in checking txs.
+const (
+	CategorySize         Category = iota + 1 //too big tx or too long fields
+	CategoryFormat                           //malformed fields
+	CategorySignature                        //invalid or missing signatures
+	CategoryPoW                              //invalid PoW
+	CategoryEasiness                         //easiness over the one in the config or not met by the hash
+	CategoryTime                             //timestamp in future
+	CategoryLockTime                         //not unlocked yet
+	CategoryBalance                          //invalid amounts
+	CategoryMissingInput                     //referred txs or outputs are not found
+)
+
+func (c Category) String() string {
+	switch c {
+	case CategorySize:
+		return "size"
+	case CategoryFormat:
+		return "format"
+	case CategorySignature:
+		return "signature"
+	case CategoryPoW:
+		return "pow"
+	case CategoryEasiness:
+		return "easiness"
+	case CategoryTime:
+		return "time"
+	case CategoryLockTime:
+		return "locktime"
+	case CategoryBalance:
+		return "balance"
+	case CategoryMissingInput:
+		return "missing input"
+	default:
+		return ""
+	}
+}
+
+func (c Category) Error() string {
+	return "invalid tx " + c.String()
+}
+
+//ValidationError is an error returned by Check and CheckAll.
+type ValidationError struct {
+	Category Category
+	Field    string //name of the field in JSON, e.g. "inputs", or empty
+	Index    int    //index in the field, or -1
+	Err      error  //underlying error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+//Unwrap returns the underlying error.
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+//Is returns true if target is the category of e.
+func (e *ValidationError) Is(target error) bool {
+	c, ok := target.(Category)
+	return ok && c == e.Category
+}
+
+func validationError(c Category, field string, index int, err error) error {
+	return &ValidationError{
+		Category: c,
+		Field:    field,
+		Index:    index,
+		Err:      err,
+	}
+}
+
+func validationErrorf(c Category, field string, index int, format string, a ...interface{}) error {
+	return validationError(c, field, index, fmt.Errorf(format, a...))
+}
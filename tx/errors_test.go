@@ -0,0 +1,111 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+func checkCategory(t *testing.T, err error, c Category, field string, index int) {
+	t.Helper()
+	if !errors.Is(err, c) {
+		t.Error("category should be", c, err)
+		return
+	}
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Error("should be ValidationError", err)
+		return
+	}
+	if verr.Field != field || verr.Index != index {
+		t.Error("invalid field or index", verr.Field, verr.Index, err)
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	tr := New(aklib.DebugConfig, one, zero)
+	err := tr.Check(aklib.DebugConfig, TypeNormal)
+	checkCategory(t, err, CategoryPoW, "nonce", -1)
+	if err.Error() != "nonce must be 42 size, but 0" {
+		t.Error("message should not be changed", err)
+	}
+	if errors.Is(err, CategorySize) {
+		t.Error("category should not be size")
+	}
+
+	tr = New(aklib.DebugConfig, one, zero)
+	tr.Message = make([]byte, MessageMax+1)
+	checkCategory(t, tr.Check(aklib.DebugConfig, TypeNotPoWed), CategorySize, "message", -1)
+
+	tr = New(aklib.DebugConfig, one, zero)
+	tr.Time = time.Now().Add(time.Hour)
+	checkCategory(t, tr.Check(aklib.DebugConfig, TypeNotPoWed), CategoryTime, "time", -1)
+
+	tr = New(aklib.DebugConfig, one, zero)
+	tr.LockTime = time.Now().Add(time.Hour)
+	checkCategory(t, tr.Check(aklib.DebugConfig, TypeNotPoWed), CategoryLockTime, "lock_time", -1)
+
+	tr = New(aklib.DebugConfig, one, zero)
+	tr.Easiness = aklib.DebugConfig.Easiness
+	checkCategory(t, tr.Check(aklib.TestConfig, TypeNotPoWed), CategoryEasiness, "easiness", -1)
+
+	tr = New(aklib.DebugConfig, one, zero)
+	tr.Easiness = 1
+	checkCategory(t, tr.checkEasiness(), CategoryEasiness, "easiness", -1)
+
+	tr = New(aklib.DebugConfig, one, zero)
+	tr.AddInput(one, 0)
+	tr.AddInput(one, 0)
+	checkCategory(t, tr.Check(aklib.DebugConfig, TypeNotPoWed), CategoryFormat, "inputs", 1)
+
+	m := make(store)
+	var d1 [32]byte
+	d1[0] = 1
+	m[d1] = New(aklib.DebugConfig).Body
+	if err = m[d1].AddOutput(aklib.DebugConfig, a[0].Address58(aklib.DebugConfig), 100); err != nil {
+		t.Error(err)
+	}
+
+	tr = New(aklib.DebugConfig, one, two)
+	checkCategory(t, tr.CheckAll(aklib.DebugConfig, m.GetTX, TypeNotPoWed), CategoryMissingInput, "parent", 1)
+
+	tr = New(aklib.DebugConfig, one)
+	tr.AddInput(one, 1)
+	checkCategory(t, tr.CheckAll(aklib.DebugConfig, m.GetTX, TypeNotPoWed), CategoryMissingInput, "inputs", 0)
+
+	tr = New(aklib.DebugConfig, one)
+	tr.AddInput(one, 0)
+	checkCategory(t, tr.CheckAll(aklib.DebugConfig, m.GetTX, TypeNotPoWed), CategorySignature, "inputs", 0)
+
+	tr = New(aklib.DebugConfig, one)
+	tr.AddInput(one, 0)
+	if err = tr.AddOutput(aklib.DebugConfig, a[1].Address58(aklib.DebugConfig), 99); err != nil {
+		t.Error(err)
+	}
+	if err = tr.Sign(a[0]); err != nil {
+		t.Error(err)
+	}
+	checkCategory(t, tr.CheckAll(aklib.DebugConfig, m.GetTX, TypeNotPoWed), CategoryBalance, "", -1)
+}
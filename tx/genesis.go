@@ -0,0 +1,154 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/AidosKuneen/aklib"
+	"github.com/AidosKuneen/aklib/address"
+)
+
+//NewGenesis returns the genesis tx which pays cfg.Genesis.
+//Nodes must construct the genesis tx in the same way to get the same hash:
+//a normal tx without inputs, signatures and nonce, whose time is the unix epoch,
+//whose easiness is cfg.Easiness and whose outputs are sorted by
+//base58 addresses, so the genesis tx is determined by cfg.
+func NewGenesis(cfg *aklib.Config) (*Transaction, error) {
+	if len(cfg.Genesis) > ArrayMax {
+		return nil, fmt.Errorf("number of genesis addresses must be less than %d", ArrayMax+1)
+	}
+	adrs := make([]string, 0, len(cfg.Genesis))
+	for adr := range cfg.Genesis {
+		adrs = append(adrs, adr)
+	}
+	sort.Strings(adrs)
+	tr := &Transaction{
+		Body: &Body{
+			Type:     typeNormal,
+			Time:     time.Unix(0, 0).UTC(),
+			Easiness: cfg.Easiness,
+		},
+	}
+	for _, adr := range adrs {
+		if err := tr.AddOutput(cfg, adr, cfg.Genesis[adr]); err != nil {
+			return nil, err
+		}
+	}
+	return tr, nil
+}
+
+//regtestLeads are candidates of the char following "AKPRIV", "AKADRS",
+//"AKMSIG", "AKNKEY" and "AKNODE" in base58 strings of regtest networks.
+const regtestLeads = "RUWXZabcdefghijkmnopqrstuvwxyz"
+
+//regtestPrefixes returns prefixes for a regtest network, i.e.
+//PrefixPriv, PrefixAdrs, PrefixMsig, PrefixNkey and PrefixNode in this order,
+//whose base58 strings do not start with the same chars as networks in aklib.Configs.
+func regtestPrefixes() ([][]byte, error) {
+	kinds := []struct {
+		lead byte
+		n    int
+	}{
+		//32 bytes seed + 4 bytes checksum
+		{'V', 32 + 4},
+		{'S', 32},
+		{'G', 32},
+		{'Y', 32 + 4},
+		{'E', 32},
+	}
+	used := make(map[string]struct{})
+	for _, c := range aklib.Configs {
+		for i, p := range [][]byte{c.PrefixPriv, c.PrefixAdrs, c.PrefixMsig, c.PrefixNkey, c.PrefixNode} {
+			dat := make([]byte, len(p)+kinds[i].n)
+			copy(dat, p)
+			used[address.Encode58(dat)[:2]] = struct{}{}
+		}
+	}
+next:
+	for _, l := range []byte(regtestLeads) {
+		ps := make([][]byte, len(kinds))
+		for i, k := range kinds {
+			lead := string([]byte{k.lead, l})
+			if _, ok := used[lead]; ok {
+				continue next
+			}
+			p, err := address.Prefix58(lead, k.n)
+			if err != nil {
+				continue next
+			}
+			ps[i] = p
+		}
+		return ps, nil
+	}
+	return nil, errors.New("no prefixes are available for regtest")
+}
+
+//Regtest returns a Config for a local network copied from template,
+//and its genesis tx.
+//The Config is named name, has the easiest easiness and
+//prefixes whose base58 strings differ from networks in aklib.Configs,
+//e.g. "AKADRSR" for addresses.
+//Its genesis tx pays genesis, whose keys are addresses in template
+//and whose total must be ADKSupply. They are re-encoded for the Config.
+func Regtest(template *aklib.Config, name string, genesis map[string]uint64) (*aklib.Config, *Transaction, error) {
+	if len(genesis) == 0 {
+		return nil, nil, errors.New("genesis must not be empty")
+	}
+	ps, err := regtestPrefixes()
+	if err != nil {
+		return nil, nil, err
+	}
+	cfg := *template
+	cfg.Name = name
+	cfg.Easiness = math.MaxUint32
+	cfg.TicketEasiness = math.MaxUint32
+	cfg.PrefixPriv = ps[0]
+	cfg.PrefixAdrs = ps[1]
+	cfg.PrefixMsig = ps[2]
+	cfg.PrefixNkey = ps[3]
+	cfg.PrefixNode = ps[4]
+	cfg.DNS = nil
+	cfg.Genesis = make(map[string]uint64, len(genesis))
+	for adr, v := range genesis {
+		pub, isNode, err := address.ParseAddress58(template, adr)
+		if err != nil || isNode {
+			return nil, nil, fmt.Errorf("invalid genesis address %s", adr)
+		}
+		radr, err := address.Address58(&cfg, append(append([]byte{}, cfg.PrefixAdrs...), pub[2:]...))
+		if err != nil {
+			return nil, nil, err
+		}
+		cfg.Genesis[radr] = v
+	}
+	if err := address.ValidateConfig(&cfg); err != nil {
+		return nil, nil, err
+	}
+	tr, err := NewGenesis(&cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &cfg, tr, nil
+}
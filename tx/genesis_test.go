@@ -0,0 +1,137 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AidosKuneen/aklib"
+	"github.com/AidosKuneen/aklib/address"
+)
+
+func TestNewGenesis(t *testing.T) {
+	gen, err := NewGenesis(aklib.DebugConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//nodes must derive the same hash from DebugConfig.
+	if h := hex.EncodeToString(gen.Hash()); h != "7aedb067f624f270d7f45b552e7e8a7fb0babd489c2d6a15590e7361b6ae0485" {
+		t.Error("invalid genesis hash", h)
+	}
+	if !gen.Time.Equal(time.Unix(0, 0)) || len(gen.Inputs) != 0 || len(gen.Outputs) != 1 {
+		t.Error("invalid genesis", gen)
+	}
+}
+
+func TestRegtest(t *testing.T) {
+	adr0 := a[0].Address58(aklib.DebugConfig)
+	adr1 := a[1].Address58(aklib.DebugConfig)
+	cfg, gen, err := Regtest(aklib.DebugConfig, "regtest", map[string]uint64{
+		adr0: aklib.ADKSupply - aklib.ADK,
+		adr1: aklib.ADK,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "regtest" || cfg.Easiness != 0xffffffff || cfg.TicketEasiness != 0xffffffff {
+		t.Error("invalid config", cfg)
+	}
+	radr0 := a[0].Address58(cfg)
+	if !strings.HasPrefix(radr0, "AKADRSR") || cfg.Genesis[radr0] != aklib.ADKSupply-aklib.ADK {
+		t.Error("invalid genesis", cfg.Genesis)
+	}
+	if err = aklib.RegisterConfig(cfg); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		aklib.Configs = aklib.Configs[:len(aklib.Configs)-1]
+	}()
+	for _, s := range []string{radr0, adr0} {
+		info, err := address.Inspect(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (info.Config == cfg) != (s == radr0) {
+			t.Error("invalid network", s, info.Config.Name)
+		}
+	}
+	cfg2, _, err := Regtest(aklib.DebugConfig, "regtest2", map[string]uint64{adr0: aklib.ADKSupply})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = aklib.RegisterConfig(cfg2); err != nil {
+		t.Fatal(err)
+	}
+	aklib.Configs = aklib.Configs[:len(aklib.Configs)-1]
+	if strings.HasPrefix(a[0].Address58(cfg2), "AKADRSR") {
+		t.Error("prefixes should differ from registered ones")
+	}
+	if aklib.DebugConfig.Genesis[adr0] != 0 {
+		t.Error("template should not be changed")
+	}
+	gen2, err := NewGenesis(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(gen.Hash(), gen2.Hash()) {
+		t.Error("genesis should be determined by config")
+	}
+	idx := byte(0)
+	if adr1 < adr0 {
+		idx = 1
+	}
+	if !bytes.Equal(gen.Outputs[idx].Address, a[0].Address(cfg)) ||
+		gen.Outputs[idx].Value != aklib.ADKSupply-aklib.ADK {
+		t.Error("invalid genesis output")
+	}
+
+	m := make(store)
+	m[gen.Hash().Array()] = gen.Body
+	tr := New(cfg, gen.Hash())
+	tr.AddInput(gen.Hash(), idx)
+	if err = tr.AddOutput(cfg, a[2].Address58(cfg), aklib.ADKSupply-aklib.ADK); err != nil {
+		t.Error(err)
+	}
+	if err = tr.Sign(a[0]); err != nil {
+		t.Error(err)
+	}
+	if err = tr.PoW(); err != nil {
+		t.Error(err)
+	}
+	if err = tr.CheckAll(cfg, m.GetTX, TypeNormal); err != nil {
+		t.Error(err)
+	}
+
+	for _, g := range []map[string]uint64{
+		nil,
+		{adr0: aklib.ADKSupply - 1},
+		{adr0: aklib.ADKSupply - aklib.ADK, "AKADRinvalid": aklib.ADK},
+		{adr0: aklib.ADKSupply - aklib.ADK, a[1].Address58(aklib.TestConfig): aklib.ADK},
+	} {
+		if _, _, err := Regtest(aklib.DebugConfig, "regtest", g); err == nil {
+			t.Error("should be error", g)
+		}
+	}
+}
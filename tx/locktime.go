@@ -0,0 +1,109 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+//IsUnlocked returns true if the tx is not locked at now.
+func (tr *Transaction) IsUnlocked(now time.Time) bool {
+	return tr.LockTime.IsZero() || !tr.LockTime.After(now)
+}
+
+//LockedTxs keeps pre-signed txs which are locked until their LockTime
+//and tells when they can be broadcast.
+//It is safe for concurrent use.
+type LockedTxs struct {
+	Now   func() time.Time //clock, time.Now if nil
+	mutex sync.Mutex
+	txs   []*Transaction //sorted by LockTime
+}
+
+func (l *LockedTxs) now() time.Time {
+	if l.Now == nil {
+		return time.Now()
+	}
+	return l.Now()
+}
+
+//Add adds a locked tx.
+func (l *LockedTxs) Add(tr *Transaction) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	i := sort.Search(len(l.txs), func(i int) bool {
+		return l.txs[i].LockTime.After(tr.LockTime)
+	})
+	l.txs = append(l.txs, nil)
+	copy(l.txs[i+1:], l.txs[i:])
+	l.txs[i] = tr
+}
+
+//Remove removes the tx, e.g. when it was canceled by spending its inputs.
+//It returns false if tr is not in l.
+func (l *LockedTxs) Remove(tr *Transaction) bool {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for i, t := range l.txs {
+		if t == tr {
+			l.txs = append(l.txs[:i], l.txs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+//Len returns the number of txs in l.
+func (l *LockedTxs) Len() int {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	return len(l.txs)
+}
+
+//Next returns the earliest LockTime of txs in l.
+//It returns false if l is empty.
+func (l *LockedTxs) Next() (time.Time, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	if len(l.txs) == 0 {
+		return time.Time{}, false
+	}
+	return l.txs[0].LockTime, true
+}
+
+//Ready removes and returns txs which are unlocked now, in order of LockTime.
+func (l *LockedTxs) Ready() []*Transaction {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	now := l.now()
+	n := sort.Search(len(l.txs), func(i int) bool {
+		return !l.txs[i].IsUnlocked(now)
+	})
+	if n == 0 {
+		return nil
+	}
+	ready := make([]*Transaction, n)
+	copy(ready, l.txs)
+	l.txs = l.txs[n:]
+	return ready
+}
@@ -0,0 +1,137 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+type fakeClock struct {
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	return c.now
+}
+
+func TestBuildLocked(t *testing.T) {
+	cfg := aklib.DebugConfig
+	values := []uint64{8, 1, 21}
+	w := &testWallet{
+		utxos:  testUTXOs(values...),
+		change: a[4],
+	}
+	m := make(store)
+	m[zero32()] = New(cfg).Body
+	m[one32()] = New(cfg).Body
+	for i, v := range values {
+		if err := m[zero32()].AddOutput(cfg, a[i%len(a)].Address58(cfg), v); err != nil {
+			t.Error(err)
+		}
+	}
+	lock := time.Now().Add(time.Hour + time.Millisecond)
+	tr, err := Build2(cfg, w, &BuildParam{
+		Dest: []*RawOutput{
+			{Address: a[0].Address58(cfg), Value: 20},
+		},
+		PoWType:  TypeRewardFee,
+		Fee:      1,
+		LockTime: lock,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !tr.LockTime.Equal(lock.Truncate(time.Second)) {
+		t.Error("invalid locktime", tr.LockTime)
+	}
+	//the clock starts from the time of the tx not to be before it.
+	clock := &fakeClock{now: tr.Time}
+	opt := &CheckOption{
+		Now: clock.Now,
+	}
+	err = tr.CheckAllWithOption(cfg, m.GetTX, TypeRewardFee, opt)
+	checkCategory(t, err, CategoryLockTime, "lock_time", -1)
+	if tr.IsUnlocked(clock.now) {
+		t.Error("should be locked")
+	}
+
+	clock.now = tr.LockTime
+	if !tr.IsUnlocked(clock.now) {
+		t.Error("should be unlocked")
+	}
+	if err = tr.CheckAllWithOption(cfg, m.GetTX, TypeRewardFee, opt); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestLockedTxs(t *testing.T) {
+	cfg := aklib.DebugConfig
+	clock := &fakeClock{now: time.Unix(1500000000, 0)}
+	l := &LockedTxs{
+		Now: clock.Now,
+	}
+	if _, ok := l.Next(); ok {
+		t.Error("should be empty")
+	}
+	txs := make([]*Transaction, 4)
+	for i, d := range []time.Duration{3, 1, 0, 2} {
+		txs[i] = New(cfg, one)
+		if d != 0 {
+			txs[i].LockTime = clock.now.Add(d * time.Hour)
+		}
+		l.Add(txs[i])
+	}
+	if l.Len() != 4 {
+		t.Error("invalid length", l.Len())
+	}
+	if next, ok := l.Next(); !ok || !next.IsZero() {
+		t.Error("invalid next", next)
+	}
+	ready := l.Ready()
+	if len(ready) != 1 || ready[0] != txs[2] {
+		t.Error("invalid ready txs", ready)
+	}
+	if ready = l.Ready(); len(ready) != 0 {
+		t.Error("should be empty", ready)
+	}
+	if next, ok := l.Next(); !ok || !next.Equal(clock.now.Add(time.Hour)) {
+		t.Error("invalid next", next)
+	}
+
+	clock.now = clock.now.Add(2 * time.Hour)
+	ready = l.Ready()
+	if len(ready) != 2 || ready[0] != txs[1] || ready[1] != txs[3] {
+		t.Error("invalid ready txs", ready)
+	}
+	if !l.Remove(txs[0]) {
+		t.Error("should be removed")
+	}
+	if l.Remove(txs[0]) {
+		t.Error("should not be removed")
+	}
+	clock.now = clock.now.Add(2 * time.Hour)
+	if ready = l.Ready(); len(ready) != 0 || l.Len() != 0 {
+		t.Error("should be empty", ready)
+	}
+}
@@ -0,0 +1,361 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/AidosKuneen/aklib"
+	"github.com/AidosKuneen/aklib/address"
+)
+
+//PartialVersion is the version of PartialTransaction.
+const PartialVersion = 1
+
+//PartialTransaction is a tx being signed by co-signers,
+//which can be passed among them in JSON.
+//It holds outputs referred by the tx so that co-signers can
+//check it without the store.
+type PartialTransaction struct {
+	Version      byte           `json:"version"`
+	Body         *Body          `json:"body"`
+	Signatures   Signatures     `json:"signatures"`
+	Inputs       []*Output      `json:"inputs"`                  //outputs referred by Body.Inputs
+	MultiSigIns  []*MultiSigOut `json:"multisig_ins"`            //multisig outputs referred by Body.MultiSigIns
+	TicketOutput address.Bytes  `json:"ticket_output,omitempty"` //ticket output referred by Body.TicketInput
+}
+
+//Missing is a report of signatures still needed for an input.
+type Missing struct {
+	Field     string   `json:"field"` //"inputs", "multisig_ins" or "ticket_input"
+	Index     int      `json:"index"`
+	Need      int      `json:"need"`      //number of signatures still needed
+	Addresses []string `json:"addresses"` //addresses which can sign
+}
+
+//NewPartial returns a PartialTransaction of tr, whose referred outputs
+//are got by getTX.
+func NewPartial(cfg *aklib.Config, tr *Transaction, getTX GetTXFunc) (*PartialTransaction, error) {
+	tr2 := tr.Clone()
+	p := &PartialTransaction{
+		Version:     PartialVersion,
+		Body:        tr2.Body,
+		Inputs:      make([]*Output, len(tr2.Inputs)),
+		MultiSigIns: make([]*MultiSigOut, len(tr2.MultiSigIns)),
+	}
+	for n, inp := range tr2.Inputs {
+		inTX, err := getTX(inp.PreviousTX)
+		if err != nil {
+			return nil, validationError(CategoryMissingInput, "inputs", n, err)
+		}
+		if len(inTX.Outputs) <= int(inp.Index) {
+			return nil, validationErrorf(CategoryMissingInput, "inputs", n, "invalid input index, should be under  %d", len(inTX.Outputs))
+		}
+		p.Inputs[n] = inTX.Outputs[inp.Index]
+	}
+	for n, inp := range tr2.MultiSigIns {
+		inTX, err := getTX(inp.PreviousTX)
+		if err != nil {
+			return nil, validationError(CategoryMissingInput, "multisig_ins", n, err)
+		}
+		if len(inTX.MultiSigOuts) <= int(inp.Index) {
+			return nil, validationErrorf(CategoryMissingInput, "multisig_ins", n, "invalid multisig index, should be under  %d", len(inTX.MultiSigOuts))
+		}
+		p.MultiSigIns[n] = inTX.MultiSigOuts[inp.Index]
+	}
+	if len(tr2.TicketInput) > 0 {
+		inTX, err := getTX(tr2.TicketInput)
+		if err != nil {
+			return nil, validationError(CategoryMissingInput, "ticket_input", -1, err)
+		}
+		p.TicketOutput = inTX.TicketOutput
+	}
+	for _, sig := range tr2.Signatures {
+		if err := p.AddSig(cfg, sig); err != nil {
+			return nil, err
+		}
+	}
+	return p, nil
+}
+
+//ParsePartial parses a PartialTransaction in JSON.
+//Referred outputs in it are not verified against txs of inputs,
+//because they are not in the JSON. Co-signers who have the store should
+//make their own PartialTransaction by NewPartial and Merge the parsed one into it,
+//instead of trusting values and addresses of the referred outputs.
+func ParsePartial(dat []byte) (*PartialTransaction, error) {
+	var p PartialTransaction
+	if err := json.Unmarshal(dat, &p); err != nil {
+		return nil, err
+	}
+	if p.Version != PartialVersion {
+		return nil, fmt.Errorf("unknown version %d", p.Version)
+	}
+	if p.Body == nil {
+		return nil, errors.New("body is null")
+	}
+	if len(p.Inputs) != len(p.Body.Inputs) || len(p.MultiSigIns) != len(p.Body.MultiSigIns) {
+		return nil, errors.New("number of referred outputs does not match inputs")
+	}
+	for _, o := range p.Inputs {
+		if o == nil {
+			return nil, errors.New("referred output is null")
+		}
+	}
+	for _, o := range p.MultiSigIns {
+		if o == nil {
+			return nil, errors.New("referred multisig output is null")
+		}
+	}
+	if (len(p.Body.TicketInput) > 0) != (len(p.TicketOutput) > 0) {
+		return nil, errors.New("referred ticket output does not match ticket input")
+	}
+	return &p, nil
+}
+
+//Transaction returns the tx with the signatures collected so far.
+func (p *PartialTransaction) Transaction() *Transaction {
+	return &Transaction{
+		Body:       p.Body,
+		Signatures: p.Signatures,
+	}
+}
+
+//Sign signs the tx by a and adds the signature.
+func (p *PartialTransaction) Sign(cfg *aklib.Config, a *address.Address) error {
+	sig, err := p.Transaction().Signature(a)
+	if err != nil {
+		return err
+	}
+	return p.AddSig(cfg, sig)
+}
+
+//AddSig verifies the signature and adds it.
+//The signer must be needed by inputs of the tx, and multisig inputs must
+//not have more signatures than M.
+//It does nothing if a signature by the same public key already exists.
+func (p *PartialTransaction) AddSig(cfg *aklib.Config, sig *address.Signature) error {
+	for _, s := range p.Signatures {
+		if bytes.Equal(s.PublicKey, sig.PublicKey) {
+			return nil
+		}
+	}
+	dat, err := p.Transaction().bytesForSign()
+	if err != nil {
+		return validationError(CategoryFormat, "hash_type", -1, err)
+	}
+	if err := sig.Verify(dat); err != nil {
+		return validationErrorf(CategorySignature, "signatures", len(p.Signatures), "failed to verify a signature at %d: %v", len(p.Signatures), err)
+	}
+	adr := sig.Address(cfg, false)
+	used := false
+	for _, o := range p.Inputs {
+		if bytes.Equal(o.Address, adr) {
+			used = true
+		}
+	}
+	if bytes.Equal(p.TicketOutput, adr) {
+		used = true
+	}
+	for n, mul := range p.MultiSigIns {
+		if !hasBytes(mul.Addresses, adr) {
+			continue
+		}
+		used = true
+		if p.signed(cfg, mul.Addresses) >= int(mul.M) {
+			return validationErrorf(CategorySignature, "multisig_ins", n, "multisig %d already has %d signatures", n, mul.M)
+		}
+	}
+	if !used {
+		return validationErrorf(CategorySignature, "signatures", len(p.Signatures), "signer is not needed by inputs")
+	}
+	p.Signatures = append(p.Signatures, sig)
+	return nil
+}
+
+//Merge adds signatures in others, which must be for the same tx.
+func (p *PartialTransaction) Merge(cfg *aklib.Config, others ...*PartialTransaction) error {
+	dat, err := p.Transaction().bytesForSign()
+	if err != nil {
+		return err
+	}
+	for _, o := range others {
+		if o.Version != p.Version {
+			return fmt.Errorf("version %d does not match %d", o.Version, p.Version)
+		}
+		odat, err := o.Transaction().bytesForSign()
+		if err != nil {
+			return err
+		}
+		if !bytes.Equal(dat, odat) {
+			return errors.New("cannot merge different txs")
+		}
+		for _, sig := range o.Signatures {
+			if err := p.AddSig(cfg, sig); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+//Missing returns inputs which still need signatures.
+func (p *PartialTransaction) Missing(cfg *aklib.Config) []*Missing {
+	var ms []*Missing
+	for n, o := range p.Inputs {
+		if p.signed(cfg, []address.Bytes{o.Address}) == 0 {
+			ms = append(ms, &Missing{
+				Field:     "inputs",
+				Index:     n,
+				Need:      1,
+				Addresses: address58s(cfg, o.Address),
+			})
+		}
+	}
+	for n, mul := range p.MultiSigIns {
+		need := int(mul.M) - p.signed(cfg, mul.Addresses)
+		if need <= 0 {
+			continue
+		}
+		var adrs []address.Bytes
+		for _, adr := range mul.Addresses {
+			if p.signed(cfg, []address.Bytes{adr}) == 0 {
+				adrs = append(adrs, adr)
+			}
+		}
+		ms = append(ms, &Missing{
+			Field:     "multisig_ins",
+			Index:     n,
+			Need:      need,
+			Addresses: address58s(cfg, adrs...),
+		})
+	}
+	if len(p.TicketOutput) > 0 && p.signed(cfg, []address.Bytes{p.TicketOutput}) == 0 {
+		ms = append(ms, &Missing{
+			Field:     "ticket_input",
+			Index:     -1,
+			Need:      1,
+			Addresses: address58s(cfg, p.TicketOutput),
+		})
+	}
+	return ms
+}
+
+//Finalize checks the tx is fully signed and returns it.
+//The tx is checked in the same way as CheckAll except PoW.
+//A time-locked tx is checked as at its LockTime if it is still locked,
+//so that it can be pre-signed.
+func (p *PartialTransaction) Finalize(cfg *aklib.Config) (*Transaction, error) {
+	if ms := p.Missing(cfg); len(ms) > 0 {
+		return nil, validationErrorf(CategorySignature, ms[0].Field, ms[0].Index, "%d more signatures are needed", ms[0].Need)
+	}
+	tr := p.Transaction().Clone()
+	opt := &CheckOption{
+		Now: func() time.Time {
+			if now := time.Now(); now.After(tr.LockTime) {
+				return now
+			}
+			return tr.LockTime
+		},
+	}
+	if err := tr.CheckAllWithOption(cfg, p.getTX, TypeNotPoWed, opt); err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+//signed returns the number of adrs which signed the tx.
+func (p *PartialTransaction) signed(cfg *aklib.Config, adrs []address.Bytes) int {
+	n := 0
+	for _, sig := range p.Signatures {
+		if hasBytes(adrs, sig.Address(cfg, false)) {
+			n++
+		}
+	}
+	return n
+}
+
+//getTX returns bodies which have only outputs referred by the tx.
+func (p *PartialTransaction) getTX(h []byte) (*Body, error) {
+	body := &Body{}
+	found := false
+	for n, inp := range p.Body.Inputs {
+		if !bytes.Equal(inp.PreviousTX, h) {
+			continue
+		}
+		for len(body.Outputs) <= int(inp.Index) {
+			body.Outputs = append(body.Outputs, &Output{})
+		}
+		body.Outputs[inp.Index] = p.Inputs[n]
+		found = true
+	}
+	for n, inp := range p.Body.MultiSigIns {
+		if !bytes.Equal(inp.PreviousTX, h) {
+			continue
+		}
+		for len(body.MultiSigOuts) <= int(inp.Index) {
+			body.MultiSigOuts = append(body.MultiSigOuts, &MultiSigOut{})
+		}
+		body.MultiSigOuts[inp.Index] = p.MultiSigIns[n]
+		found = true
+	}
+	if bytes.Equal(p.Body.TicketInput, h) {
+		body.TicketOutput = p.TicketOutput
+		found = true
+	}
+	for _, par := range p.Body.Parent {
+		if bytes.Equal(par, h) {
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("%x not found", h)
+	}
+	return body, nil
+}
+
+func hasBytes(adrs []address.Bytes, adr []byte) bool {
+	for _, a := range adrs {
+		if bytes.Equal(a, adr) {
+			return true
+		}
+	}
+	return false
+}
+
+func address58s(cfg *aklib.Config, adrs ...address.Bytes) []string {
+	ss := make([]string, 0, len(adrs))
+	for _, adr := range adrs {
+		s := hex.EncodeToString(adr)
+		if len(adr) == 34 {
+			if s58, err := address.Address58(cfg, adr); err == nil {
+				s = s58
+			}
+		}
+		ss = append(ss, s)
+	}
+	return ss
+}
@@ -0,0 +1,172 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+func TestPartial(t *testing.T) {
+	cfg := aklib.DebugConfig
+	var d1 [32]byte
+	d1[0] = 0x1
+	m := make(store)
+	m[d1] = New(cfg).Body
+	if err := m[d1].AddOutput(cfg, a[1].Address58(cfg), 100); err != nil {
+		t.Error(err)
+	}
+	if err := m[d1].AddMultisigOut(cfg, 2, 123,
+		a[2].Address58(cfg), a[3].Address58(cfg), a[4].Address58(cfg)); err != nil {
+		t.Error(err)
+	}
+	tr := New(cfg, one)
+	tr.AddInput(one, 0)
+	tr.AddMultisigIn(one, 0)
+	if err := tr.AddOutput(cfg, a[0].Address58(cfg), 223); err != nil {
+		t.Error(err)
+	}
+
+	p, err := NewPartial(cfg, tr, m.GetTX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p.Sign(cfg, a[1]); err != nil {
+		t.Error(err)
+	}
+	if err = p.Sign(cfg, a[0]); err == nil {
+		t.Error("should be error")
+	}
+	ms := p.Missing(cfg)
+	if len(ms) != 1 || ms[0].Field != "multisig_ins" || ms[0].Index != 0 || ms[0].Need != 2 ||
+		len(ms[0].Addresses) != 3 {
+		t.Fatal("invalid missing", ms)
+	}
+	if _, err = p.Finalize(cfg); err == nil {
+		t.Error("should be error")
+	}
+
+	dat, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p2, err := ParsePartial(dat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p3, err := ParsePartial(dat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = p2.Sign(cfg, a[2]); err != nil {
+		t.Error(err)
+	}
+	if err = p3.Sign(cfg, a[3]); err != nil {
+		t.Error(err)
+	}
+	ms = p3.Missing(cfg)
+	if len(ms) != 1 || ms[0].Need != 1 || len(ms[0].Addresses) != 2 ||
+		ms[0].Addresses[0] != a[2].Address58(cfg) {
+		t.Error("invalid missing", ms[0])
+	}
+	if err = p.Merge(cfg, p2, p3); err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Missing(cfg)) != 0 {
+		t.Error("should not be missing")
+	}
+	if err = p.Sign(cfg, a[4]); err == nil {
+		t.Error("should be error")
+	}
+
+	p4, err := ParsePartial(dat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p4.Body.Outputs[0].Value = 222
+	if err = p.Merge(cfg, p4); err == nil {
+		t.Error("should be error")
+	}
+
+	tr2, err := p.Finalize(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tr2.PoW(); err != nil {
+		t.Error(err)
+	}
+	if err = tr2.CheckAll(cfg, m.GetTX, TypeNormal); err != nil {
+		t.Error(err)
+	}
+
+	for _, s := range []string{
+		`{"version":2,"body":{}}`,
+		`{"version":1}`,
+		`{"version":1,"body":{"inputs":[{}]},"inputs":[]}`,
+		`{"version":1,"body":{"inputs":[{}]},"inputs":[null]}`,
+	} {
+		if _, err := ParsePartial([]byte(s)); err == nil {
+			t.Error("should be error", s)
+		}
+	}
+}
+
+func TestPartialLocked(t *testing.T) {
+	cfg := aklib.DebugConfig
+	m := make(store)
+	m[one32()] = New(cfg).Body
+	if err := m[one32()].AddMultisigOut(cfg, 2, 123,
+		a[2].Address58(cfg), a[3].Address58(cfg), a[4].Address58(cfg)); err != nil {
+		t.Error(err)
+	}
+	tr := New(cfg, one)
+	tr.LockTime = time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	tr.AddMultisigIn(one, 0)
+	if err := tr.AddOutput(cfg, a[0].Address58(cfg), 123); err != nil {
+		t.Error(err)
+	}
+	p, err := NewPartial(cfg, tr, m.GetTX)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, adr := range a[2:4] {
+		if err = p.Sign(cfg, adr); err != nil {
+			t.Error(err)
+		}
+	}
+	tr2, err := p.Finalize(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = tr2.CheckAll(cfg, m.GetTX, TypeNotPoWed)
+	checkCategory(t, err, CategoryLockTime, "lock_time", -1)
+	opt := &CheckOption{
+		Now: func() time.Time {
+			return tr.LockTime
+		},
+	}
+	if err = tr2.CheckAllWithOption(cfg, m.GetTX, TypeNotPoWed, opt); err != nil {
+		t.Error(err)
+	}
+}
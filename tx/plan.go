@@ -0,0 +1,221 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/AidosKuneen/aklib"
+	"github.com/AidosKuneen/aklib/address"
+	"github.com/AidosKuneen/cuckoo"
+)
+
+//FeePolicy is a policy of fees for txs.
+type FeePolicy struct {
+	PerByte   uint64 //fee per byte of a tx
+	Min       uint64 //minimum fee
+	Max       uint64 //maximum fee, or no limit if 0
+	PreferFee bool   //pay a fee even if a ticket is available
+}
+
+//Plan is a plan for building a tx.
+type Plan struct {
+	PoWType         Type
+	Fee             uint64 //fee to be paid, 0 if PoWType is not TypeRewardFee
+	Size            int    //estimated size of the tx in bytes
+	Inputs          int    //number of inputs to be used
+	Change          uint64 //value of the change output
+	TicketAvailable bool
+}
+
+//PlanBuild decides the PoW type and the fee of a tx built by Build2 with p
+//from the ticket availability in w, the estimated tx size and policy,
+//and fills them in p.
+//It selects a ticket if available and policy does not prefer fees,
+//or pays a fee otherwise.
+//Nothing is signed and no change address is made,
+//but GetTicketout of w is called to check a ticket.
+//If policy is nil, the minimum fee is used.
+func PlanBuild(conf *aklib.Config, w Wallet2, p *BuildParam, policy *FeePolicy) (*Plan, error) {
+	if policy == nil {
+		policy = &FeePolicy{}
+	}
+	pl := &Plan{}
+	h, ticketadr, err := w.GetTicketout()
+	if err == nil && len(h) == 32 && ticketadr != nil {
+		pl.TicketAvailable = true
+	}
+	feePlan, feeErr := planFee(conf, w, p, policy)
+	switch {
+	case pl.TicketAvailable && (!policy.PreferFee || feeErr != nil):
+		tp, err := planSize(conf, w, p, TypeRewardTicket, 0, ticketadr)
+		if err != nil {
+			return nil, err
+		}
+		tp.TicketAvailable = true
+		pl = tp
+	case feeErr != nil:
+		return nil, feeErr
+	default:
+		feePlan.TicketAvailable = pl.TicketAvailable
+		pl = feePlan
+	}
+	p.PoWType = pl.PoWType
+	p.Fee = pl.Fee
+	return pl, nil
+}
+
+func planFee(conf *aklib.Config, w Wallet, p *BuildParam, policy *FeePolicy) (*Plan, error) {
+	fee := policy.Min
+	if fee == 0 {
+		fee = 1
+	}
+	for i := 0; i < 10; i++ {
+		pl, err := planSize(conf, w, p, TypeRewardFee, fee, nil)
+		if err != nil {
+			return nil, err
+		}
+		f := policy.PerByte * uint64(pl.Size)
+		if f <= fee {
+			if policy.Max > 0 && fee > policy.Max {
+				return nil, errors.New("fee is over the max of the policy")
+			}
+			return pl, nil
+		}
+		fee = f
+	}
+	return nil, errors.New("cannot decide the fee")
+}
+
+//planSize estimates the size of the tx built by Build2 with p.
+//ticketadr is the address of the ticket output for TypeRewardTicket.
+func planSize(conf *aklib.Config, w Wallet, p *BuildParam, typ Type, fee uint64, ticketadr *address.Address) (*Plan, error) {
+	ls, err := w.GetLeaves()
+	if err != nil {
+		return nil, err
+	}
+	tr := New(conf, ls...)
+	tr.Message = []byte(p.Comment)
+	var outtotal uint64
+	for _, o := range p.Dest {
+		outtotal += o.Value
+	}
+	outtotal += fee
+	utxos, err := w.GetUTXO(outtotal)
+	if err != nil {
+		return nil, err
+	}
+	sel := p.Selector
+	if sel == nil {
+		sel = LargestFirst{}
+	}
+	selected, err := sel.Select(utxos, outtotal)
+	if err != nil {
+		return nil, err
+	}
+	var intotal uint64
+	signers := make(map[string]struct{})
+	for _, u := range selected {
+		tr.AddInput(u.Hash, u.Index)
+		intotal += u.Value
+		signers[signerKey(conf, u)] = struct{}{}
+	}
+	if intotal > outtotal {
+		tr.Outputs = append(tr.Outputs, &Output{
+			Address: dummyAddress(conf),
+			Value:   intotal - outtotal,
+		})
+	}
+	for _, o := range p.Dest {
+		if err := tr.AddOutput(conf, o.Address, o.Value); err != nil {
+			return nil, err
+		}
+	}
+	switch typ {
+	case TypeRewardFee:
+		tr.HashType = HashTypeExcludeOutputs | 0x1
+		tr.Outputs = append(tr.Outputs, &Output{
+			Value: fee,
+		})
+	case TypeRewardTicket:
+		tr.HashType = HashTypeExcludeTicketOut
+		tr.TicketInput = make(Hash, 32)
+		//the ticket address signs only once even if it is also a signer of inputs.
+		signers[string(ticketadr.Address(conf))] = struct{}{}
+	default:
+		fillDummyPoW(tr)
+	}
+	sig, err := dummySignature(conf)
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(signers); i++ {
+		tr.Signatures = append(tr.Signatures, sig)
+	}
+	pl := &Plan{
+		PoWType: typ,
+		Size:    tr.Size(),
+		Inputs:  len(selected),
+		Change:  intotal - outtotal,
+	}
+	if typ == TypeRewardFee {
+		pl.Fee = fee
+	}
+	return pl, nil
+}
+
+//signerKey returns a key of the signer of u, i.e. address bytes
+//derived from the public key of u.Address.
+//If the public key is unknown, the key is unique to u, so that
+//u is regarded as signed by another signer and the size is not underestimated.
+func signerKey(conf *aklib.Config, u *UTXO) string {
+	if a, ok := u.Address.(interface{ PublicKey() []byte }); ok {
+		sig := &address.Signature{PublicKey: a.PublicKey()}
+		return string(sig.Address(conf, false))
+	}
+	return fmt.Sprintf("%x/%s/%d", u.Hash, u.Type, u.Index)
+}
+
+//fillDummyPoW fills nonces whose size is the max.
+func fillDummyPoW(tr *Transaction) {
+	tr.Nonce = make([]uint32, cuckoo.ProofSize)
+	for i := range tr.Nonce {
+		tr.Nonce[i] = math.MaxUint32
+	}
+	tr.Gnonce = math.MaxUint32
+}
+
+func dummyAddress(conf *aklib.Config) address.Bytes {
+	adr := make(address.Bytes, 34)
+	copy(adr, conf.PrefixAdrs)
+	return adr
+}
+
+//dummySignature returns a signature whose size is same as real ones.
+func dummySignature(conf *aklib.Config) (*address.Signature, error) {
+	a, err := address.New(conf, make([]byte, 32))
+	if err != nil {
+		return nil, err
+	}
+	return a.Sign(nil)
+}
@@ -0,0 +1,150 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"testing"
+
+	"github.com/AidosKuneen/aklib"
+	"github.com/AidosKuneen/aklib/address"
+)
+
+//labeledAddress is an address whose String() is a label in a wallet.
+type labeledAddress struct {
+	*address.Address
+}
+
+func (a *labeledAddress) Sign(tr *Transaction) error {
+	return tr.Sign(a.Address)
+}
+
+func (a *labeledAddress) String() string {
+	return "savings"
+}
+
+//nearSize returns true if estimated size e is within 5% of a,
+//because lengths of signatures may differ.
+func nearSize(a, e int) bool {
+	d := a - e
+	if d < 0 {
+		d = -d
+	}
+	return d <= a/20
+}
+
+func TestPlanBuild(t *testing.T) {
+	cfg := aklib.DebugConfig
+	w := &testWallet{
+		utxos:  testUTXOs(8000, 1000, 21000, 3000, 13000, 2000, 5000),
+		change: a[4],
+	}
+	newParam := func() *BuildParam {
+		return &BuildParam{
+			Comment: "test",
+			Dest: []*RawOutput{
+				{Address: a[0].Address58(cfg), Value: 20000},
+			},
+		}
+	}
+
+	p := newParam()
+	pl, err := PlanBuild(cfg, w, p, &FeePolicy{PerByte: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pl.TicketAvailable || pl.PoWType != TypeRewardTicket || pl.Fee != 0 ||
+		p.PoWType != TypeRewardTicket || p.Fee != 0 || pl.Inputs != 1 || pl.Change != 1000 {
+		t.Error("invalid plan", pl)
+	}
+	tr, err := Build2(cfg, w, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !nearSize(tr.Size(), pl.Size) {
+		t.Error("invalid size", tr.Size(), pl.Size)
+	}
+
+	p = newParam()
+	pl, err = PlanBuild(cfg, w, p, &FeePolicy{PerByte: 1, PreferFee: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pl.TicketAvailable || pl.PoWType != TypeRewardFee || pl.Fee != uint64(pl.Size) ||
+		p.PoWType != TypeRewardFee || p.Fee != pl.Fee {
+		t.Error("invalid plan", pl)
+	}
+	tr, err = Build2(cfg, w, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !nearSize(tr.Size(), pl.Size) {
+		t.Error("invalid size", tr.Size(), pl.Size)
+	}
+	if err = tr.Check(cfg, TypeRewardFee); err != nil {
+		t.Error(err)
+	}
+
+	w.noTicket = true
+	p = newParam()
+	pl, err = PlanBuild(cfg, w, p, &FeePolicy{Min: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pl.TicketAvailable || pl.PoWType != TypeRewardFee || pl.Fee != 10 {
+		t.Error("invalid plan", pl)
+	}
+	if _, err = PlanBuild(cfg, w, newParam(), &FeePolicy{PerByte: 1, Max: 10}); err == nil {
+		t.Error("should be error")
+	}
+	w.noTicket = false
+	pl, err = PlanBuild(cfg, w, newParam(), &FeePolicy{PerByte: 1, Max: 10, PreferFee: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pl.PoWType != TypeRewardTicket {
+		t.Error("invalid plan", pl)
+	}
+	p = newParam()
+	p.Dest[0].Value = 60000
+	if _, err = PlanBuild(cfg, w, p, nil); err == nil {
+		t.Error("should be error")
+	}
+
+	//the ticket address a[4] also signs an input,
+	//whose String() is not a base58 address.
+	w.utxos = testUTXOs(1000, 1000, 1000, 1000, 30000)
+	w.utxos[4].Address = &labeledAddress{a[4]}
+	p = newParam()
+	pl, err = PlanBuild(cfg, w, p, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pl.PoWType != TypeRewardTicket || pl.Inputs != 1 {
+		t.Error("invalid plan", pl)
+	}
+	tr, err = Build2(cfg, w, p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tr.Signatures) != 1 || !nearSize(tr.Size(), pl.Size) {
+		t.Error("invalid size", len(tr.Signatures), tr.Size(), pl.Size)
+	}
+}
@@ -23,9 +23,14 @@ package tx
 import (
 	"context"
 	"errors"
+	"math"
+	"sync/atomic"
+	"time"
 
+	"github.com/AidosKuneen/aklib"
 	"github.com/AidosKuneen/aklib/rand"
 	"github.com/AidosKuneen/cuckoo"
+	"github.com/AidosKuneen/numcpu"
 )
 
 //PoW does PoW.
@@ -36,17 +41,88 @@ func (tx *Transaction) PoW() error {
 //ErrCanceled means the PoW was canceled by context.
 var ErrCanceled = errors.New("PoW was canceled")
 
+var errPoWFailed = errors.New("failed to PoW")
+
 //PoWContext does PoW with context..
 func (tx *Transaction) PoWContext(ctx context.Context) error {
-	cu := cuckoo.NewCuckoo()
+	return tx.powRange(ctx, rand.R.Uint32(), math.MaxUint32, nil)
+}
+
+//PoWParallel does PoW with n workers.
+func (tx *Transaction) PoWParallel(n int) error {
+	return tx.PoWParallelContext(context.Background(), n)
+}
+
+//PoWParallelContext does PoW with context by n workers.
+//Each worker searches its own range of Gnonce with its own cuckoo instance,
+//and the first solution cancels the others.
+//If n <= 0, the number of CPUs is used.
+func (tx *Transaction) PoWParallelContext(ctx context.Context, n int) error {
+	return tx.powParallel(ctx, n, nil)
+}
+
+func (tx *Transaction) powParallel(ctx context.Context, n int, st *powStat) error {
+	if n <= 0 {
+		n = numcpu.NumCPU()
+	}
+	if n == 1 {
+		return tx.powRange(ctx, rand.R.Uint32(), math.MaxUint32, st)
+	}
+	ctx2, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	type result struct {
+		tx  *Transaction
+		err error
+	}
+	results := make(chan result, n)
 	start := rand.R.Uint32()
-	for tx.Gnonce = start; tx.Gnonce != start-1; tx.Gnonce++ {
+	count := uint32(math.MaxUint32 / uint32(n))
+	for i := 0; i < n; i++ {
+		go func(tr *Transaction, start uint32) {
+			err := tr.powRange(ctx2, start, count, st)
+			if err == nil {
+				cancel()
+			}
+			results <- result{tr, err}
+		}(tx.Clone(), start+uint32(i)*count)
+	}
+	var found *Transaction
+	for i := 0; i < n; i++ {
+		r := <-results
+		if r.err == nil && found == nil {
+			found = r.tx
+		}
+	}
+	if found != nil {
+		tx.Gnonce = found.Gnonce
+		tx.Nonce = found.Nonce
+		return nil
+	}
+	if ctx.Err() != nil {
+		return ErrCanceled
+	}
+	return errPoWFailed
+}
+
+//powRange does PoW for count Gnonces from start.
+//st is updated if not nil.
+func (tx *Transaction) powRange(ctx context.Context, start, count uint32, st *powStat) error {
+	cu := cuckoo.NewCuckoo()
+	for i := uint32(0); i < count; i++ {
+		tx.Gnonce = start + i
 		hs := tx.hashForPoW()
 		nonces, found := cu.PoW(hs)
+		if st != nil {
+			atomic.AddUint64(&st.attempts, 1)
+			if found {
+				atomic.AddUint64(&st.cycles, 1)
+			}
+		}
 		if found {
 			tx.Nonce = nonces
 			if isValidHash(tx.Hash(), tx.Easiness) {
-				break
+				return nil
 			}
 		}
 		select {
@@ -55,8 +131,128 @@ func (tx *Transaction) PoWContext(ctx context.Context) error {
 		default:
 		}
 	}
-	if tx.Gnonce == start-1 {
-		return errors.New("failed to PoW")
+	return errPoWFailed
+}
+
+type powStat struct {
+	attempts uint64
+	cycles   uint64
+	start    time.Time
+}
+
+func (st *powStat) progress(easiness uint32) *Progress {
+	p := &Progress{
+		Attempts: atomic.LoadUint64(&st.attempts),
+		Cycles:   atomic.LoadUint64(&st.cycles),
+		Elapsed:  time.Since(st.start),
+	}
+	p.Remaining = expectedDuration(p.Cycles, p.Elapsed, easiness)
+	return p
+}
+
+//expectedDuration returns the expected duration to find a hash within easiness
+//when cycles were found in elapsed.
+//It returns 0 if no cycle was found.
+func expectedDuration(cycles uint64, elapsed time.Duration, easiness uint32) time.Duration {
+	if cycles == 0 {
+		return 0
+	}
+	d := float64(elapsed) / float64(cycles) * (1 << 32) / (float64(easiness) + 1)
+	if d >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
+
+//Progress is a progress of PoW.
+type Progress struct {
+	Attempts uint64        //number of tried Gnonces
+	Cycles   uint64        //number of found cuckoo cycles
+	Elapsed  time.Duration //elapsed time from the start
+	//Remaining is the estimated time until a solution is found.
+	//Because each trial is independent, this does not decrease as time goes by.
+	//This is 0 if no cycle is found yet.
+	Remaining time.Duration
+}
+
+//PoWProgressContext does PoW with context by n workers like PoWParallelContext,
+//and calls f with the progress every interval (a second if <= 0) and when finished.
+//f is called in another goroutine. To receive progress by a channel,
+//send it in f.
+func (tx *Transaction) PoWProgressContext(ctx context.Context, n int, interval time.Duration,
+	f func(*Progress)) error {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	st := &powStat{
+		start: time.Now(),
+	}
+	done := make(chan struct{})
+	fin := make(chan struct{})
+	go func() {
+		defer close(fin)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				f(st.progress(tx.Easiness))
+			}
+		}
+	}()
+	err := tx.powParallel(ctx, n, st)
+	close(done)
+	<-fin
+	f(st.progress(tx.Easiness))
+	return err
+}
+
+//Estimator estimates duration of PoW on this machine.
+type Estimator struct {
+	Workers int           //number of workers
+	Cycles  uint64        //number of found cuckoo cycles in calibration
+	Elapsed time.Duration //duration of calibration
+}
+
+//Calibrate runs PoW with n workers for d and returns an Estimator.
+//If n <= 0, the number of CPUs is used.
+func Calibrate(n int, d time.Duration) (*Estimator, error) {
+	if n <= 0 {
+		n = numcpu.NumCPU()
+	}
+	tr := &Transaction{
+		Body: &Body{
+			Type: typeNormal,
+			Time: time.Now().Truncate(time.Second),
+		},
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), d)
+	defer cancel()
+	st := &powStat{
+		start: time.Now(),
 	}
-	return nil
+	if err := tr.powParallel(ctx, n, st); err != nil && err != ErrCanceled {
+		return nil, err
+	}
+	p := st.progress(0)
+	if p.Cycles == 0 {
+		return nil, errors.New("no cuckoo cycle was found, calibrate longer")
+	}
+	return &Estimator{
+		Workers: n,
+		Cycles:  p.Cycles,
+		Elapsed: p.Elapsed,
+	}, nil
+}
+
+//Duration returns the expected duration of PoW with easiness.
+func (e *Estimator) Duration(easiness uint32) time.Duration {
+	return expectedDuration(e.Cycles, e.Elapsed, easiness)
+}
+
+//Config returns the expected durations of PoW for normal txs and tickets in cfg.
+func (e *Estimator) Config(cfg *aklib.Config) (time.Duration, time.Duration) {
+	return e.Duration(cfg.Easiness), e.Duration(cfg.TicketEasiness)
 }
@@ -0,0 +1,96 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+func TestPoWParallel(t *testing.T) {
+	for _, n := range []int{0, 1, 4} {
+		tr := New(aklib.DebugConfig, one, zero)
+		if err := tr.Sign(a[0]); err != nil {
+			t.Error(err)
+		}
+		if err := tr.PoWParallel(n); err != nil {
+			t.Error(err)
+		}
+		if err := tr.Check(aklib.DebugConfig, TypeNormal); err != nil {
+			t.Error(n, err)
+		}
+	}
+}
+
+func TestPoWCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	for _, n := range []int{1, 4} {
+		tr := New(aklib.DebugConfig, one, zero)
+		tr.Easiness = 0
+		if err := tr.PoWParallelContext(ctx, n); err != ErrCanceled {
+			t.Error("should be canceled", n, err)
+		}
+	}
+}
+
+func TestPoWProgress(t *testing.T) {
+	tr := New(aklib.DebugConfig, one, zero)
+	var ps []*Progress
+	if err := tr.PoWProgressContext(context.Background(), 2, time.Millisecond, func(p *Progress) {
+		ps = append(ps, p)
+	}); err != nil {
+		t.Error(err)
+	}
+	if len(ps) == 0 {
+		t.Fatal("no progress")
+	}
+	p := ps[len(ps)-1]
+	if p.Attempts == 0 || p.Cycles == 0 || p.Cycles > p.Attempts || p.Elapsed == 0 || p.Remaining == 0 {
+		t.Error("invalid progress", p)
+	}
+}
+
+func TestEstimator(t *testing.T) {
+	e, err := Calibrate(2, 2*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Workers != 2 || e.Cycles == 0 || e.Elapsed < 2*time.Second {
+		t.Error("invalid estimator", e)
+	}
+	d0 := e.Duration(0xffffffff)
+	d1 := e.Duration(0x7fffffff)
+	if d0 <= 0 || d1 <= d0 || d1 > 3*d0 {
+		t.Error("invalid duration", d0, d1)
+	}
+	if e.Duration(0) != math.MaxInt64 && e.Duration(0) < d1 {
+		t.Error("invalid duration", e.Duration(0))
+	}
+	tx, ticket := e.Config(aklib.MainConfig)
+	if ticket <= tx {
+		t.Error("ticket PoW should take longer", tx, ticket)
+	}
+}
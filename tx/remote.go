@@ -0,0 +1,175 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/AidosKuneen/aklib/arypack"
+)
+
+//Work is a unit of PoW which can be done remotely.
+type Work struct {
+	TX       ByteSlice `json:"tx"` //tx without nonces in arypack
+	Easiness uint32    `json:"easiness"`
+}
+
+//Solution is a result of Work.
+type Solution struct {
+	Gnonce uint32   `json:"g_nonce"`
+	Nonce  []uint32 `json:"nonce"`
+}
+
+//NewWork returns a Work for PoW of tr.
+func NewWork(tr *Transaction) *Work {
+	tx2 := tr.Clone()
+	tx2.Gnonce = 0
+	tx2.Nonce = nil
+	return &Work{
+		TX:       arypack.Marshal(tx2),
+		Easiness: tr.Easiness,
+	}
+}
+
+//Do does PoW of the work with context by n workers like PoWParallelContext.
+func (w *Work) Do(ctx context.Context, n int) (*Solution, error) {
+	var tr Transaction
+	if err := arypack.Unmarshal(w.TX, &tr); err != nil {
+		return nil, err
+	}
+	if tr.Body == nil {
+		return nil, errors.New("body is null")
+	}
+	if tr.Easiness != w.Easiness {
+		return nil, errors.New("easiness does not match the tx")
+	}
+	if err := tr.PoWParallelContext(ctx, n); err != nil {
+		return nil, err
+	}
+	return &Solution{
+		Gnonce: tr.Gnonce,
+		Nonce:  tr.Nonce,
+	}, nil
+}
+
+//ApplySolution checks the solution of PoW in the same way as Check
+//and sets it to tr if valid.
+func (tr *Transaction) ApplySolution(s *Solution) error {
+	gnonce, nonce := tr.Gnonce, tr.Nonce
+	tr.Gnonce, tr.Nonce = s.Gnonce, s.Nonce
+	err := tr.checkNonce()
+	if err == nil {
+		err = tr.checkEasiness()
+	}
+	if err != nil {
+		tr.Gnonce, tr.Nonce = gnonce, nonce
+	}
+	return err
+}
+
+//PoWServer is an http.Handler which does PoW of Works in JSON POSTed by
+//RemotePoW and responds Solutions in JSON.
+//It does one Work at a time.
+//It has no authentication, so it should be used only in trusted networks.
+type PoWServer struct {
+	Workers int //number of workers for PoW, or the number of CPUs if <= 0
+	once    sync.Once
+	sem     chan struct{}
+}
+
+func (s *PoWServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method must be POST", http.StatusMethodNotAllowed)
+		return
+	}
+	var work Work
+	//a work is under TransactionMax in hex
+	if err := json.NewDecoder(io.LimitReader(r.Body, 2*TransactionMax+1024)).Decode(&work); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.once.Do(func() {
+		s.sem = make(chan struct{}, 1)
+	})
+	//wait for the running work without ignoring the cancel of the request.
+	select {
+	case s.sem <- struct{}{}:
+	case <-r.Context().Done():
+		http.Error(w, r.Context().Err().Error(), http.StatusServiceUnavailable)
+		return
+	}
+	sol, err := work.Do(r.Context(), s.Workers)
+	<-s.sem
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sol); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+//RemotePoW does PoW of tr with context by the PoWServer at url,
+//checks the solution and sets it to tr.
+//If client is nil, http.DefaultClient is used.
+func (tr *Transaction) RemotePoW(ctx context.Context, client *http.Client, url string) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	dat, err := json.Marshal(NewWork(tr))
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(dat))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return ErrCanceled
+		}
+		return err
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(res.Body, 1024*1024))
+	if err != nil {
+		return err
+	}
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("remote PoW failed: %s: %s", res.Status, bytes.TrimSpace(body))
+	}
+	var sol Solution
+	if err := json.Unmarshal(body, &sol); err != nil {
+		return err
+	}
+	return tr.ApplySolution(&sol)
+}
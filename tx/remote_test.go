@@ -0,0 +1,106 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+func TestRemotePoW(t *testing.T) {
+	s := httptest.NewServer(&PoWServer{Workers: 2})
+	defer s.Close()
+
+	tr := New(aklib.DebugConfig, one, zero)
+	if err := tr.Sign(a[0]); err != nil {
+		t.Error(err)
+	}
+	if err := tr.RemotePoW(context.Background(), nil, s.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Check(aklib.DebugConfig, TypeNormal); err != nil {
+		t.Error(err)
+	}
+
+	gnonce := tr.Gnonce
+	if err := tr.ApplySolution(&Solution{
+		Gnonce: gnonce + 1,
+		Nonce:  tr.Nonce,
+	}); err == nil {
+		t.Error("should be error")
+	}
+	if tr.Gnonce != gnonce {
+		t.Error("should not be changed")
+	}
+	if err := tr.ApplySolution(&Solution{
+		Gnonce: gnonce,
+		Nonce:  tr.Nonce[1:],
+	}); err == nil {
+		t.Error("should be error")
+	}
+
+	w := NewWork(tr)
+	w.Easiness++
+	if _, err := w.Do(context.Background(), 1); err == nil {
+		t.Error("should be error")
+	}
+	w = &Work{TX: []byte{1, 2, 3}}
+	if _, err := w.Do(context.Background(), 1); err == nil {
+		t.Error("should be error")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	tr = New(aklib.DebugConfig, one, zero)
+	tr.Easiness = 0
+	if err := tr.RemotePoW(ctx, nil, s.URL); err != ErrCanceled {
+		t.Error("should be canceled", err)
+	}
+}
+
+func TestPoWServerWait(t *testing.T) {
+	s := &PoWServer{Workers: 1}
+	tr := New(aklib.DebugConfig, one, zero)
+	dat, err := json.Marshal(NewWork(tr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	//another work is running.
+	s.once.Do(func() {
+		s.sem = make(chan struct{}, 1)
+	})
+	s.sem <- struct{}{}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	r := httptest.NewRequest(http.MethodPost, "/", bytes.NewReader(dat)).WithContext(ctx)
+	w := httptest.NewRecorder()
+	s.ServeHTTP(w, r)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Error("should be canceled while waiting", w.Code)
+	}
+}
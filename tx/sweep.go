@@ -0,0 +1,275 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/AidosKuneen/aklib"
+	"github.com/AidosKuneen/aklib/address"
+)
+
+//SweepParam is a param for sweeping UTXOs.
+type SweepParam struct {
+	Dest      string //address to send all coins
+	Comment   string
+	Fee       uint64    //fee for each tx, or no fee (TypeNormal) if 0
+	MaxInputs int       //max number of inputs and of multisig inputs in a tx, ArrayMax if 0
+	DryRun    bool      //only plan txs without signing
+	LockTime  time.Time //txs are invalid until then if not zero
+	GetTX     GetTXFunc //gets txs of multisig UTXOs to count their signatures
+}
+
+//SweepTx is a tx made by Sweep.
+type SweepTx struct {
+	UTXOs   []*UTXO
+	Value   uint64              //value sent to Dest
+	Size    int                 //estimated size of the tx
+	Tx      *Transaction        //signed tx, nil if DryRun or co-signers must sign
+	Partial *PartialTransaction //tx signed by the wallet which co-signers must sign, or nil
+}
+
+//errUnderFee is returned by planSweep if the total of UTXOs is not over the fee.
+var errUnderFee = errors.New("total of UTXOs is not over the fee")
+
+//Sweep builds and signs txs which send all UTXOs returned by
+//w.GetUTXO(math.MaxUint64) to p.Dest.
+//Each tx has at most p.MaxInputs inputs and multisig inputs, and
+//its size is under TransactionMax.
+//UTXOs whose total in a tx is not over p.Fee are skipped and returned
+//without being sent.
+//p.GetTX is needed if there are multisig UTXOs.
+//If multisig UTXOs need signatures of co-signers after the wallet signed,
+//the tx is returned as Partial with the missing signers instead of Tx.
+//If p.DryRun, it only returns the plan without signing.
+func Sweep(conf *aklib.Config, w Wallet, p *SweepParam) ([]*SweepTx, []*UTXO, error) {
+	max := p.MaxInputs
+	if max <= 0 || max > ArrayMax {
+		max = ArrayMax
+	}
+	utxos, err := w.GetUTXO(math.MaxUint64)
+	if err != nil {
+		return nil, nil, err
+	}
+	ls, err := w.GetLeaves()
+	if err != nil {
+		return nil, nil, err
+	}
+	sig, err := dummySignature(conf)
+	if err != nil {
+		return nil, nil, err
+	}
+	signers, err := sweepSigners(conf, utxos, p.GetTX)
+	if err != nil {
+		return nil, nil, err
+	}
+	var sts []*SweepTx
+	var skipped []*UTXO
+	for len(utxos) > 0 {
+		st, err := planSweep(conf, ls, utxos, signers, p, max, sig)
+		switch {
+		case errors.Is(err, errUnderFee):
+			skipped = append(skipped, st.UTXOs...)
+		case err != nil:
+			return nil, nil, err
+		default:
+			sts = append(sts, st)
+		}
+		utxos = utxos[len(st.UTXOs):]
+		signers = signers[len(st.UTXOs):]
+	}
+	if p.DryRun {
+		return sts, skipped, nil
+	}
+	for _, st := range sts {
+		st.Tx, err = buildSweep(conf, ls, st.UTXOs, p, st.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, u := range st.UTXOs {
+			if err := u.Address.Sign(st.Tx); err != nil {
+				return nil, nil, err
+			}
+		}
+		if st.Tx.Size() > TransactionMax {
+			return nil, nil, errors.New("tx size is too big")
+		}
+		if len(st.Tx.MultiSigIns) == 0 {
+			continue
+		}
+		pt, err := NewPartial(conf, st.Tx, p.GetTX)
+		if err != nil {
+			return nil, nil, err
+		}
+		if len(pt.Missing(conf)) > 0 {
+			st.Tx, st.Partial = nil, pt
+		}
+	}
+	return sts, skipped, nil
+}
+
+//sweepSigners returns keys of signers of each of utxos by signerKey.
+//A multisig UTXO in its multisig structure got by getTX needs M signatures,
+//i.e. the one by the wallet and ones by M-1 co-signers, which are assumed to be
+//the other addresses in the order of the structure.
+func sweepSigners(conf *aklib.Config, utxos []*UTXO, getTX GetTXFunc) ([][]string, error) {
+	signers := make([][]string, len(utxos))
+	for i, u := range utxos {
+		signers[i] = []string{signerKey(conf, u)}
+		if u.Type != TypeMulout {
+			continue
+		}
+		if getTX == nil {
+			return nil, errors.New("GetTX is needed for multisig UTXOs")
+		}
+		body, err := getTX(u.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if int(u.Index) >= len(body.MultiSigOuts) {
+			return nil, fmt.Errorf("multisig output %d of %s is not found", u.Index, u.Hash)
+		}
+		mout := body.MultiSigOuts[u.Index]
+		if int(mout.M) > len(mout.Addresses) {
+			return nil, fmt.Errorf("invalid multisig output %d of %s", u.Index, u.Hash)
+		}
+		for _, b := range mout.Addresses {
+			if len(signers[i]) >= int(mout.M) {
+				break
+			}
+			if string(b) != signers[i][0] {
+				signers[i] = append(signers[i], string(b))
+			}
+		}
+	}
+	return signers, nil
+}
+
+//planSweep returns a SweepTx which has as many UTXOs from the head of utxos
+//as possible. signers are addresses which sign each of utxos.
+//If the total of the UTXOs is not over the fee, it returns errUnderFee
+//with a SweepTx which has only the UTXOs.
+func planSweep(conf *aklib.Config, ls []Hash, utxos []*UTXO, signers [][]string,
+	p *SweepParam, max int, sig *address.Signature) (*SweepTx, error) {
+	var nin, nmul int
+	n := 0
+	for ; n < len(utxos); n++ {
+		if utxos[n].Type == TypeMulout {
+			nmul++
+		} else {
+			nin++
+		}
+		if nin > max || nmul > max {
+			break
+		}
+	}
+	plan := func(n int) (*SweepTx, error) {
+		var total uint64
+		adrs := make(map[string]struct{})
+		for i, u := range utxos[:n] {
+			total += u.Value
+			for _, adr := range signers[i] {
+				adrs[adr] = struct{}{}
+			}
+		}
+		if total <= p.Fee {
+			return nil, fmt.Errorf("%w: total %v, fee %v", errUnderFee, total, p.Fee)
+		}
+		tr, err := buildSweep(conf, ls, utxos[:n], p, total-p.Fee)
+		if err != nil {
+			return nil, err
+		}
+		for range adrs {
+			tr.Signatures = append(tr.Signatures, sig)
+		}
+		if p.Fee == 0 {
+			fillDummyPoW(tr)
+		}
+		return &SweepTx{
+			UTXOs: utxos[:n],
+			Value: total - p.Fee,
+			Size:  tr.Size(),
+		}, nil
+	}
+	st, err := plan(n)
+	if errors.Is(err, errUnderFee) {
+		return &SweepTx{UTXOs: utxos[:n]}, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	if st.Size <= TransactionMax {
+		return st, nil
+	}
+	//search the max number of UTXOs whose tx is under TransactionMax.
+	var best *SweepTx
+	lo, hi := 0, n
+	for hi-lo > 1 {
+		mid := (lo + hi) / 2
+		st2, err := plan(mid)
+		switch {
+		case errors.Is(err, errUnderFee):
+			//use more UTXOs.
+			lo = mid
+		case err != nil:
+			return nil, err
+		case st2.Size <= TransactionMax:
+			lo, best = mid, st2
+		default:
+			hi = mid
+		}
+	}
+	if best == nil {
+		return nil, errors.New("tx size is too big")
+	}
+	return best, nil
+}
+
+func buildSweep(conf *aklib.Config, ls []Hash, utxos []*UTXO, p *SweepParam, v uint64) (*Transaction, error) {
+	tr := New(conf, ls...)
+	tr.Message = []byte(p.Comment)
+	if !p.LockTime.IsZero() {
+		tr.LockTime = p.LockTime.Truncate(time.Second)
+	}
+	for _, u := range utxos {
+		switch u.Type {
+		case TypeOut:
+			tr.AddInput(u.Hash, u.Index)
+		case TypeMulout:
+			tr.AddMultisigIn(u.Hash, u.Index)
+		default:
+			return nil, fmt.Errorf("cannot sweep UTXO of %s", u.Type)
+		}
+	}
+	if err := tr.AddOutput(conf, p.Dest, v); err != nil {
+		return nil, err
+	}
+	if p.Fee > 0 {
+		tr.HashType = HashTypeExcludeOutputs | 0x1
+		tr.Outputs = append(tr.Outputs, &Output{
+			Value: p.Fee,
+		})
+	}
+	return tr, nil
+}
@@ -0,0 +1,219 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"testing"
+
+	"github.com/AidosKuneen/aklib"
+	"github.com/AidosKuneen/aklib/address"
+)
+
+func TestSweep(t *testing.T) {
+	cfg := aklib.DebugConfig
+	values := []uint64{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	w := &testWallet{
+		utxos: testUTXOs(values...),
+	}
+	m := make(store)
+	m[zero32()] = New(cfg).Body
+	m[one32()] = New(cfg).Body
+	for i, v := range values {
+		if err := m[zero32()].AddOutput(cfg, a[i%len(a)].Address58(cfg), v); err != nil {
+			t.Error(err)
+		}
+	}
+	dest := a[0].Address58(cfg)
+
+	sts, skipped, err := Sweep(cfg, w, &SweepParam{
+		Dest:      dest,
+		MaxInputs: 4,
+		DryRun:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sts) != 3 || len(sts[0].UTXOs) != 4 || len(sts[2].UTXOs) != 2 ||
+		sts[0].Value != 10 || sts[1].Value != 26 || sts[2].Value != 19 {
+		t.Fatal("invalid plan", sts)
+	}
+	for _, st := range sts {
+		if st.Tx != nil || st.Size == 0 {
+			t.Error("invalid plan", st)
+		}
+	}
+
+	sts, skipped, err = Sweep(cfg, w, &SweepParam{
+		Dest:      dest,
+		MaxInputs: 4,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sts) != 3 {
+		t.Fatal("invalid txs", sts)
+	}
+	for _, st := range sts {
+		if err = st.Tx.CheckAll(cfg, m.GetTX, TypeNotPoWed); err != nil {
+			t.Error(err)
+		}
+		fillDummyPoW(st.Tx)
+		if !nearSize(st.Tx.Size(), st.Size) {
+			t.Error("invalid size", st.Tx.Size(), st.Size)
+		}
+	}
+
+	sts, skipped, err = Sweep(cfg, w, &SweepParam{
+		Dest: dest,
+		Fee:  5,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sts) != 1 || len(sts[0].Tx.Inputs) != 10 || sts[0].Value != 50 || len(skipped) != 0 {
+		t.Fatal("invalid txs", sts)
+	}
+	if err = sts[0].Tx.CheckAll(cfg, m.GetTX, TypeRewardFee); err != nil {
+		t.Error(err)
+	}
+
+	//1+2 is not over the fee.
+	sts, skipped, err = Sweep(cfg, w, &SweepParam{
+		Dest:      dest,
+		Fee:       5,
+		MaxInputs: 2,
+		DryRun:    true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sts) != 4 || !equalValues(skipped, 1, 2) || sts[0].Value != 2 {
+		t.Fatal("invalid plan", sts, skipped)
+	}
+	//the last 1 is not over the fee.
+	w.utxos = testUTXOs(10, 10, 10, 1)
+	sts, skipped, err = Sweep(cfg, w, &SweepParam{
+		Dest:      dest,
+		Fee:       5,
+		MaxInputs: 3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sts) != 1 || sts[0].Value != 25 || !equalValues(skipped, 1) {
+		t.Fatal("invalid txs", sts, skipped)
+	}
+
+	//co-signers out of the wallet.
+	var co [2]*address.Address
+	for i := range co {
+		co[i], err = address.New(cfg, address.GenerateSeed32())
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	w.utxos = testUTXOs(values...)
+	w.utxos[1].Type = TypeMulout
+	w.utxos[3].Type = TypeMulout
+	m[zero32()].MultiSigOuts = nil
+	for i := 0; i < 4; i++ {
+		//2 of a[i], co[0], co[1]
+		if err := m[zero32()].AddMultisigOut(cfg, 2, values[i], a[i].Address58(cfg),
+			co[0].Address58(cfg), co[1].Address58(cfg)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, _, err = Sweep(cfg, w, &SweepParam{
+		Dest:   dest,
+		DryRun: true,
+	}); err == nil {
+		t.Error("should be error")
+	}
+	sts, _, err = Sweep(cfg, w, &SweepParam{
+		Dest:      dest,
+		MaxInputs: 2,
+		DryRun:    true,
+		GetTX:     m.GetTX,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sts) != 4 || len(sts[0].UTXOs) != 4 || len(sts[1].UTXOs) != 2 {
+		t.Fatal("invalid plan", sts)
+	}
+	sts, _, err = Sweep(cfg, w, &SweepParam{
+		Dest:  dest,
+		GetTX: m.GetTX,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sts) != 1 || sts[0].Tx != nil || sts[0].Partial == nil {
+		t.Fatal("invalid txs", sts)
+	}
+	pt := sts[0].Partial
+	if len(pt.Body.Inputs) != 8 || len(pt.Body.MultiSigIns) != 2 {
+		t.Fatal("invalid tx", pt.Body)
+	}
+	ms := pt.Missing(cfg)
+	if len(ms) != 2 || ms[0].Field != "multisig_ins" || ms[0].Need != 1 || len(ms[0].Addresses) != 2 {
+		t.Fatal("invalid missing", ms)
+	}
+	if _, err = pt.Finalize(cfg); err == nil {
+		t.Error("should be error")
+	}
+	if err = pt.Sign(cfg, co[0]); err != nil {
+		t.Fatal(err)
+	}
+	tr, err := pt.Finalize(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tr.CheckAll(cfg, m.GetTX, TypeNotPoWed); err != nil {
+		t.Error(err)
+	}
+	fillDummyPoW(tr)
+	if !nearSize(tr.Size(), sts[0].Size) {
+		t.Error("invalid size", tr.Size(), sts[0].Size)
+	}
+
+	//the wallet cannot sign more than M for a multisig.
+	m[zero32()].MultiSigOuts[1].Addresses[1] = a[0].Address(cfg)
+	m[zero32()].MultiSigOuts[1].Addresses[2] = a[2].Address(cfg)
+	if _, _, err = Sweep(cfg, w, &SweepParam{
+		Dest:  dest,
+		GetTX: m.GetTX,
+	}); err == nil {
+		t.Error("should be error")
+	}
+}
+
+func zero32() [32]byte {
+	var z [32]byte
+	copy(z[:], zero)
+	return z
+}
+
+func one32() [32]byte {
+	var o [32]byte
+	copy(o[:], one)
+	return o
+}
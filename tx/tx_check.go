@@ -22,8 +22,6 @@ package tx
 
 import (
 	"bytes"
-	"errors"
-	"fmt"
 	"time"
 
 	"github.com/AidosKuneen/aklib"
@@ -62,8 +60,61 @@ func checkAdrsPrefix(cfg *aklib.Config, adr address.Bytes) bool {
 	return bytes.HasPrefix(adr, cfg.PrefixAdrs)
 }
 
+//CheckOption is a policy for checking txs.
+//Zero values mean the defaults, which are the same as Check.
+type CheckOption struct {
+	Now            func() time.Time //clock, time.Now by default
+	TimeSkew       time.Duration    //allowed duration of timestamps in future, 0 by default
+	TransactionMax int              //max size of a tx, TransactionMax by default
+	MessageMax     int              //max length of the message, MessageMax by default
+	ArrayMax       int              //max length of arrays, ArrayMax by default
+	Types          []Type           //permitted types of txs, all types by default
+}
+
+func (opt *CheckOption) withDefault() *CheckOption {
+	o := CheckOption{}
+	if opt != nil {
+		o = *opt
+	}
+	if o.Now == nil {
+		o.Now = time.Now
+	}
+	if o.TransactionMax == 0 {
+		o.TransactionMax = TransactionMax
+	}
+	if o.MessageMax == 0 {
+		o.MessageMax = MessageMax
+	}
+	if o.ArrayMax == 0 {
+		o.ArrayMax = ArrayMax
+	}
+	return &o
+}
+
+func (opt *CheckOption) permits(typ Type) bool {
+	if len(opt.Types) == 0 {
+		return true
+	}
+	for _, t := range opt.Types {
+		if t == typ {
+			return true
+		}
+	}
+	return false
+}
+
 //Check checks the tx.
 func (tr *Transaction) Check(cfg *aklib.Config, typ Type) error {
+	return tr.CheckWithOption(cfg, typ, nil)
+}
+
+//CheckWithOption checks the tx with the policy opt.
+//If opt is nil, the default policy is used.
+func (tr *Transaction) CheckWithOption(cfg *aklib.Config, typ Type, opt *CheckOption) error {
+	opt = opt.withDefault()
+	if !opt.permits(typ) {
+		return validationErrorf(CategoryFormat, "", -1, "type %d is not permitted", typ)
+	}
 	powed := true
 	if typ == TypeRewardFee || typ == TypeRewardTicket || typ == TypeNotPoWed {
 		powed = false
@@ -73,205 +124,220 @@ func (tr *Transaction) Check(cfg *aklib.Config, typ Type) error {
 	}
 	if typ != TypeNormal &&
 		typ != TypeRewardFee && typ != TypeRewardTicket {
-		return errors.New("invalid reward type")
+		return validationErrorf(CategoryFormat, "", -1, "invalid reward type")
 	}
-	if tr.Size() > TransactionMax {
-		return errors.New("tx size is too big")
+	if tr.Size() > opt.TransactionMax {
+		return validationErrorf(CategorySize, "", -1, "tx size is too big")
 	}
 	if tr.Body == nil {
-		return errors.New("body is null")
+		return validationErrorf(CategoryFormat, "body", -1, "body is null")
 	}
 	if !bytes.Equal(tr.Type, typeNormal) {
-		return errors.New("invalid type")
+		return validationErrorf(CategoryFormat, "type", -1, "invalid type")
 	}
 	switch powed {
 	case true:
-		if len(tr.Nonce) != cuckoo.ProofSize {
-			return fmt.Errorf("nonce must be %d size, but %d", cuckoo.ProofSize, len(tr.Nonce))
-		}
-		if err := cuckoo.Verify(tr.hashForPoW(), tr.Nonce); err != nil {
+		if err := tr.checkNonce(); err != nil {
 			return err
 		}
 	case false:
 		if len(tr.Nonce) != 0 {
-			return fmt.Errorf("nonce must be 0 size")
+			return validationErrorf(CategoryPoW, "nonce", -1, "nonce must be 0 size")
 		}
 	}
-	if tr.Time.After(time.Now()) {
-		return errors.New("timestamp is in future")
+	now := opt.Now()
+	if tr.Time.After(now.Add(opt.TimeSkew)) {
+		return validationErrorf(CategoryTime, "time", -1, "timestamp is in future")
 	}
-	if len(tr.Message) > MessageMax {
-		return fmt.Errorf("message length must be under %d bytes", MessageMax)
+	if len(tr.Message) > opt.MessageMax {
+		return validationErrorf(CategorySize, "message", -1, "message length must be under %d bytes", opt.MessageMax)
 	}
-	if len(tr.Inputs) > ArrayMax {
-		return errors.New("length of inputs is over 255")
+	if len(tr.Inputs) > opt.ArrayMax {
+		return validationErrorf(CategorySize, "inputs", -1, "length of inputs is over %d", opt.ArrayMax)
 	}
 	for n, i := range tr.Inputs {
 		if len(i.PreviousTX) != 32 {
-			return fmt.Errorf("previous tx hash at %d must be 32 bytes", n)
+			return validationErrorf(CategoryFormat, "inputs", n, "previous tx hash at %d must be 32 bytes", n)
 		}
 		for j := 0; j < n; j++ {
 			if tr.Inputs[j].Index == i.Index && bytes.Equal(tr.Inputs[j].PreviousTX, i.PreviousTX) {
-				return fmt.Errorf("input %d has a same previous and index at input %d", n, j)
+				return validationErrorf(CategoryFormat, "inputs", n, "input %d has a same previous and index at input %d", n, j)
 			}
 		}
 	}
-	if len(tr.MultiSigIns) > ArrayMax {
-		return errors.New("length of MultiSigIns is over 255")
+	if len(tr.MultiSigIns) > opt.ArrayMax {
+		return validationErrorf(CategorySize, "multisig_ins", -1, "length of MultiSigIns is over %d", opt.ArrayMax)
 	}
 	for n, i := range tr.MultiSigIns {
 		if len(i.PreviousTX) != 32 {
-			return fmt.Errorf("previous tx hash at %d must be 32 bytes", n)
+			return validationErrorf(CategoryFormat, "multisig_ins", n, "previous tx hash at %d must be 32 bytes", n)
 		}
 		for j := 0; j < n; j++ {
 			if tr.MultiSigIns[j].Index == i.Index && bytes.Equal(tr.MultiSigIns[j].PreviousTX, i.PreviousTX) {
-				return fmt.Errorf("input %d has a same previous and index at input %d", n, j)
+				return validationErrorf(CategoryFormat, "multisig_ins", n, "input %d has a same previous and index at input %d", n, j)
 			}
 		}
 	}
-	if len(tr.Outputs) > ArrayMax {
-		return errors.New("length of Outputs is over 255")
+	if len(tr.Outputs) > opt.ArrayMax {
+		return validationErrorf(CategorySize, "outputs", -1, "length of Outputs is over %d", opt.ArrayMax)
 	}
 	for n, o := range tr.Outputs {
 		if !(typ == TypeRewardFee &&
 			n == len(tr.Outputs)-1) && !checkAdrsPrefix(cfg, o.Address) {
-			return fmt.Errorf("incorrect address bytes in outputs %d", n)
+			return validationErrorf(CategoryFormat, "outputs", n, "incorrect address bytes in outputs %d", n)
 		}
 		if o.Value > aklib.ADKSupply {
-			return fmt.Errorf("value in outputs %d must be under %d adk",
+			return validationErrorf(CategoryBalance, "outputs", n, "value in outputs %d must be under %d adk",
 				n, aklib.ADKSupply)
 		}
 	}
 	if typ == TypeRewardFee &&
 		(len(tr.Outputs) == 0 || tr.Outputs[len(tr.Outputs)-1].Address != nil) {
-		return errors.New("last address of inputs must be nil")
+		return validationErrorf(CategoryFormat, "outputs", len(tr.Outputs)-1, "last address of inputs must be nil")
 	}
-	if len(tr.MultiSigOuts) > ArrayMax {
-		return errors.New("length of MultiSigOuts is over 255")
+	if len(tr.MultiSigOuts) > opt.ArrayMax {
+		return validationErrorf(CategorySize, "multisig_outs", -1, "length of MultiSigOuts is over %d", opt.ArrayMax)
 	}
 	for n, o := range tr.MultiSigOuts {
 		for i, a := range o.Addresses {
 			if !checkAdrsPrefix(cfg, a) {
-				return fmt.Errorf("incorrect address format in output %d", n)
+				return validationErrorf(CategoryFormat, "multisig_outs", n, "incorrect address format in output %d", n)
 			}
-			if len(o.Addresses) > ArrayMax {
-				return errors.New("length of MultiSigOut Addresses is over 255")
+			if len(o.Addresses) > opt.ArrayMax {
+				return validationErrorf(CategorySize, "multisig_outs", n, "length of MultiSigOut Addresses is over %d", opt.ArrayMax)
 			}
 			for j := i + 1; j < len(o.Addresses); j++ {
 				if bytes.Equal(a, o.Addresses[j]) {
-					return fmt.Errorf("multisig %d has same address in %d and %d", n, i, j)
+					return validationErrorf(CategoryFormat, "multisig_outs", n, "multisig %d has same address in %d and %d", n, i, j)
 				}
 			}
 		}
 		if o.M > byte(len(o.Addresses)) {
-			return fmt.Errorf("M at multisig %d must be under number of address %d",
+			return validationErrorf(CategoryFormat, "multisig_outs", n, "M at multisig %d must be under number of address %d",
 				n, len(o.Addresses))
 		}
 		if o.Value > aklib.ADKSupply {
-			return fmt.Errorf("value in multisig %d must be under %d adk",
+			return validationErrorf(CategoryBalance, "multisig_outs", n, "value in multisig %d must be under %d adk",
 				n, aklib.ADKSupply)
 		}
 	}
 	if len(tr.Parent) == 0 {
-		return fmt.Errorf("number of previous tx must be over 0")
+		return validationErrorf(CategoryFormat, "parent", -1, "number of previous tx must be over 0")
 	}
-	if len(tr.Parent) > ArrayMax {
-		return errors.New("length of Previous is over 255")
+	if len(tr.Parent) > opt.ArrayMax {
+		return validationErrorf(CategorySize, "parent", -1, "length of Previous is over %d", opt.ArrayMax)
 	}
 	for n, i := range tr.Parent {
 		if len(i) != 32 {
-			return fmt.Errorf("tx hash size at previous tx %d must be 32 bytes", n)
+			return validationErrorf(CategoryFormat, "parent", n, "tx hash size at previous tx %d must be 32 bytes", n)
 		}
 		for j := n + 1; j < len(tr.Parent); j++ {
 			if bytes.Equal(i, tr.Parent[j]) {
-				return fmt.Errorf("previous tx %d is same as %d", n, j)
+				return validationErrorf(CategoryFormat, "parent", n, "previous tx %d is same as %d", n, j)
 			}
 		}
 	}
 	if tr.Easiness > cfg.Easiness {
-		return fmt.Errorf("Easiness must be %d", cfg.Easiness)
+		return validationErrorf(CategoryEasiness, "easiness", -1, "Easiness must be %d", cfg.Easiness)
 	}
-	if !tr.LockTime.IsZero() && tr.LockTime.After(time.Now()) {
-		return errors.New("this tx is not unlocked yet")
+	if !tr.IsUnlocked(now) {
+		return validationErrorf(CategoryLockTime, "lock_time", -1, "this tx is not unlocked yet")
 	}
 	if tr.HashType != 0 &&
 		(tr.HashType&HashTypeExcludeOutputs == 0 && tr.HashType&HashTypeExcludeTicketOut == 0) {
-		return fmt.Errorf("invalid hashtype %d", tr.HashType)
+		return validationErrorf(CategoryFormat, "hash_type", -1, "invalid hashtype %d", tr.HashType)
 	}
 	n := int(tr.HashType) & 0xf
 	switch typ {
 	case TypeRewardFee:
 		if tr.HashType&0xfff0 != HashTypeExcludeOutputs {
-			return errors.New("hashtype of reward with fee must be 0x1X")
+			return validationErrorf(CategoryFormat, "hash_type", -1, "hashtype of reward with fee must be 0x1X")
 		}
 		if n != 1 {
-			return errors.New("hashtype must be 0x11")
+			return validationErrorf(CategoryFormat, "hash_type", -1, "hashtype must be 0x11")
 		}
 	case TypeRewardTicket:
 		if tr.HashType&0xfff0 != 0x20 {
-			return errors.New("hashtype of reward with Ticket must be 0x2X")
+			return validationErrorf(CategoryFormat, "hash_type", -1, "hashtype of reward with Ticket must be 0x2X")
 		}
 	}
 	if tr.HashType&HashTypeExcludeOutputs != 0 && n > len(tr.Outputs) {
-		return fmt.Errorf("number of outputs  is too large for hashtype %d", n)
+		return validationErrorf(CategoryFormat, "hash_type", -1, "number of outputs  is too large for hashtype %d", n)
 	}
 	if tr.TicketInput != nil && len(tr.TicketInput) != 32 {
-		return errors.New("ticket intput must  be 32 bytes")
+		return validationErrorf(CategoryFormat, "ticket_input", -1, "ticket intput must  be 32 bytes")
 	}
 	if tr.TicketOutput != nil && !checkAdrsPrefix(cfg, tr.TicketOutput) {
-		return errors.New("incorrect ticket output format")
+		return validationErrorf(CategoryFormat, "ticket_output", -1, "incorrect ticket output format")
 	}
 	switch typ {
 	case TypeRewardTicket:
 		if tr.TicketOutput != nil {
-			return errors.New("ticket outtput must not be filled for RewardTicket")
+			return validationErrorf(CategoryFormat, "ticket_output", -1, "ticket outtput must not be filled for RewardTicket")
 		}
 		if tr.TicketInput == nil {
-			return errors.New("ticket intput must  be filled for RewardTicket")
+			return validationErrorf(CategoryFormat, "ticket_input", -1, "ticket intput must  be filled for RewardTicket")
 		}
 	case TypeRewardFee:
 		if tr.TicketInput != nil || tr.TicketOutput != nil {
-			return errors.New("cannot use ticket")
+			return validationErrorf(CategoryFormat, "ticket_input", -1, "cannot use ticket")
 		}
 	case TypeNormal:
 		if tr.TicketInput != nil && tr.TicketOutput == nil {
-			return errors.New("ticket_output is nil but ticket_input is not nil")
+			return validationErrorf(CategoryFormat, "ticket_output", -1, "ticket_output is nil but ticket_input is not nil")
 		}
 		if tr.TicketInput == nil && tr.TicketOutput != nil {
 			//Issuing a ticket
 			if len(tr.Inputs) > 0 || len(tr.MultiSigIns) > 0 || len(tr.Outputs) > 0 || len(tr.MultiSigOuts) > 0 || !tr.LockTime.IsZero() ||
 				tr.HashType != 0 || len(tr.Signatures) != 0 {
-				return errors.New("tx content for ticket must be empty")
+				return validationErrorf(CategoryFormat, "", -1, "tx content for ticket must be empty")
 			}
 			if tr.Easiness > cfg.TicketEasiness {
-				return errors.New("PoW doesn't meet ticket difficulty")
+				return validationErrorf(CategoryEasiness, "easiness", -1, "PoW doesn't meet ticket difficulty")
 			}
 		}
 	}
 
 	if len(tr.Scripts) > 0 {
-		return errors.New("cannot use scriptsd")
+		return validationErrorf(CategoryFormat, "scripts", -1, "cannot use scriptsd")
 	}
 	if len(tr.Reserved) > 0 {
-		return errors.New("cannot use reserved field")
+		return validationErrorf(CategoryFormat, "reserved", -1, "cannot use reserved field")
 	}
 
 	dat, err := tr.bytesForSign()
 	if err != nil {
-		return err
+		return validationError(CategoryFormat, "hash_type", -1, err)
 	}
 	for n, sig := range tr.Signatures {
 		if err := sig.Verify(dat); err != nil {
-			return fmt.Errorf("failed to verify a signature at %d: %v", n, err)
+			return validationErrorf(CategorySignature, "signatures", n, "failed to verify a signature at %d: %v", n, err)
 		}
 		for nn := n + 1; nn < len(tr.Signatures); nn++ {
 			if bytes.Equal(sig.PublicKey, tr.Signatures[nn].PublicKey) {
-				return fmt.Errorf("there are same publik keys in signature at %d and %d", n, nn)
+				return validationErrorf(CategorySignature, "signatures", n, "there are same publik keys in signature at %d and %d", n, nn)
 			}
 		}
 	}
-	if powed && !isValidHash(tr.Hash(), tr.Easiness) {
-		return errors.New("tx does not match easiness")
+	if powed {
+		return tr.checkEasiness()
+	}
+	return nil
+}
+
+func (tr *Transaction) checkNonce() error {
+	if len(tr.Nonce) != cuckoo.ProofSize {
+		return validationErrorf(CategoryPoW, "nonce", -1, "nonce must be %d size, but %d", cuckoo.ProofSize, len(tr.Nonce))
+	}
+	if err := cuckoo.Verify(tr.hashForPoW(), tr.Nonce); err != nil {
+		return validationError(CategoryPoW, "nonce", -1, err)
+	}
+	return nil
+}
+
+func (tr *Transaction) checkEasiness() error {
+	if !isValidHash(tr.Hash(), tr.Easiness) {
+		return validationErrorf(CategoryEasiness, "easiness", -1, "tx does not match easiness")
 	}
 	return nil
 }
@@ -294,24 +360,24 @@ func (tr *Transaction) total(cfg *aklib.Config, getTX GetTXFunc) (uint64, uint64
 	for n, inp := range tr.Inputs {
 		inTX, err := getTX(inp.PreviousTX)
 		if err != nil {
-			return 0, 0, err
+			return 0, 0, validationError(CategoryMissingInput, "inputs", n, err)
 		}
 		if len(inTX.Outputs) <= int(inp.Index) {
-			return 0, 0, fmt.Errorf("invalid input index, should be under  %d", len(inTX.Outputs))
+			return 0, 0, validationErrorf(CategoryMissingInput, "inputs", n, "invalid input index, should be under  %d", len(inTX.Outputs))
 		}
 		totalin += inTX.Outputs[inp.Index].Value
 		inTXAdr := inTX.Outputs[inp.Index].Address
 		if !hasAddress(adrs, inTXAdr) {
-			return 0, 0, fmt.Errorf("no signature for input %d", n)
+			return 0, 0, validationErrorf(CategorySignature, "inputs", n, "no signature for input %d", n)
 		}
 	}
 	for n, inp := range tr.MultiSigIns {
 		inTX, err := getTX(inp.PreviousTX)
 		if err != nil {
-			return 0, 0, err
+			return 0, 0, validationError(CategoryMissingInput, "multisig_ins", n, err)
 		}
 		if len(inTX.MultiSigOuts) <= int(inp.Index) {
-			return 0, 0, fmt.Errorf("invalid multisig index, should be under  %d", len(inTX.MultiSigOuts))
+			return 0, 0, validationErrorf(CategoryMissingInput, "multisig_ins", n, "invalid multisig index, should be under  %d", len(inTX.MultiSigOuts))
 		}
 		mul := inTX.MultiSigOuts[inp.Index]
 		totalin += mul.Value
@@ -322,20 +388,20 @@ func (tr *Transaction) total(cfg *aklib.Config, getTX GetTXFunc) (uint64, uint64
 			}
 		}
 		if exist != int(mul.M) {
-			return 0, 0, fmt.Errorf("invalid number of valid signatures %d in multisig %d, should be %d", exist, n, mul.M)
+			return 0, 0, validationErrorf(CategorySignature, "multisig_ins", n, "invalid number of valid signatures %d in multisig %d, should be %d", exist, n, mul.M)
 		}
 	}
 	if len(tr.TicketInput) > 0 {
 		inTX, err := getTX(tr.TicketInput)
 		if err != nil {
-			return 0, 0, err
+			return 0, 0, validationError(CategoryMissingInput, "ticket_input", -1, err)
 		}
 		if !hasAddress(adrs, inTX.TicketOutput) {
-			return 0, 0, errors.New("cannot verify the ticket input")
+			return 0, 0, validationErrorf(CategorySignature, "ticket_input", -1, "cannot verify the ticket input")
 		}
 	}
 	if hasUunused(adrs) {
-		return 0, 0, errors.New("there are(is) unsed signature")
+		return 0, 0, validationErrorf(CategorySignature, "signatures", -1, "there are(is) unsed signature")
 	}
 	return totalin, totalout, nil
 }
@@ -343,12 +409,18 @@ func (tr *Transaction) total(cfg *aklib.Config, getTX GetTXFunc) (uint64, uint64
 //CheckAll checks the tx, including other txs refered by the tx..
 //Genesis block must be saved in the store
 func (tr *Transaction) CheckAll(cfg *aklib.Config, getTX GetTXFunc, typ Type) error {
-	if err := tr.Check(cfg, typ); err != nil {
+	return tr.CheckAllWithOption(cfg, getTX, typ, nil)
+}
+
+//CheckAllWithOption checks the tx with the policy opt like CheckAll.
+//If opt is nil, the default policy is used.
+func (tr *Transaction) CheckAllWithOption(cfg *aklib.Config, getTX GetTXFunc, typ Type, opt *CheckOption) error {
+	if err := tr.CheckWithOption(cfg, typ, opt); err != nil {
 		return err
 	}
-	for _, i := range tr.Parent {
+	for n, i := range tr.Parent {
 		if _, err := getTX(i); err != nil {
-			return err
+			return validationError(CategoryMissingInput, "parent", n, err)
 		}
 	}
 	tin, tout, err := tr.total(cfg, getTX)
@@ -356,7 +428,7 @@ func (tr *Transaction) CheckAll(cfg *aklib.Config, getTX GetTXFunc, typ Type) er
 		return err
 	}
 	if tin != tout {
-		return fmt.Errorf("total input ADK %v does not equal to one of output %v",
+		return validationErrorf(CategoryBalance, "", -1, "total input ADK %v does not equal to one of output %v",
 			tin, tout)
 	}
 	return nil
@@ -0,0 +1,80 @@
+// Copyright (c) 2018 Aidos Developer
+
+// Permission is hereby granted, free of charge, to any person obtaining a copy
+// of this software and associated documentation files (the "Software"), to deal
+// in the Software without restriction, including without limitation the rights
+// to use, copy, modify, merge, publish, distribute, sublicense, and/or sell
+// copies of the Software, and to permit persons to whom the Software is
+// furnished to do so, subject to the following conditions:
+
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN
+// THE SOFTWARE.
+
+package tx
+
+import (
+	"testing"
+	"time"
+
+	"github.com/AidosKuneen/aklib"
+)
+
+func TestCheckOption(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	past := func() time.Time {
+		return now.Add(-time.Hour)
+	}
+	tr := New(aklib.DebugConfig, one, zero)
+	tr.Time = now
+	if err := tr.CheckWithOption(aklib.DebugConfig, TypeNotPoWed, nil); err != nil {
+		t.Error(err)
+	}
+	err := tr.CheckWithOption(aklib.DebugConfig, TypeNotPoWed, &CheckOption{Now: past})
+	checkCategory(t, err, CategoryTime, "time", -1)
+	if err := tr.CheckWithOption(aklib.DebugConfig, TypeNotPoWed, &CheckOption{
+		Now:      past,
+		TimeSkew: time.Hour,
+	}); err != nil {
+		t.Error(err)
+	}
+
+	tr.Time = now.Add(-2 * time.Hour)
+	tr.LockTime = now.Add(time.Hour)
+	err = tr.CheckWithOption(aklib.DebugConfig, TypeNotPoWed, nil)
+	checkCategory(t, err, CategoryLockTime, "lock_time", -1)
+	if err := tr.CheckWithOption(aklib.DebugConfig, TypeNotPoWed, &CheckOption{
+		Now: func() time.Time {
+			return now.Add(2 * time.Hour)
+		},
+	}); err != nil {
+		t.Error(err)
+	}
+
+	tr = New(aklib.DebugConfig, one, zero)
+	tr.Message = []byte("hello")
+	err = tr.CheckWithOption(aklib.DebugConfig, TypeNotPoWed, &CheckOption{MessageMax: 4})
+	checkCategory(t, err, CategorySize, "message", -1)
+	err = tr.CheckWithOption(aklib.DebugConfig, TypeNotPoWed, &CheckOption{ArrayMax: 1})
+	checkCategory(t, err, CategorySize, "parent", -1)
+	if err.Error() != "length of Previous is over 1" {
+		t.Error("invalid message", err)
+	}
+	err = tr.CheckWithOption(aklib.DebugConfig, TypeNotPoWed, &CheckOption{TransactionMax: 10})
+	checkCategory(t, err, CategorySize, "", -1)
+
+	err = tr.CheckWithOption(aklib.DebugConfig, TypeNotPoWed, &CheckOption{Types: []Type{TypeNormal}})
+	checkCategory(t, err, CategoryFormat, "", -1)
+	if err := tr.CheckWithOption(aklib.DebugConfig, TypeNotPoWed, &CheckOption{
+		Types: []Type{TypeNormal, TypeNotPoWed},
+	}); err != nil {
+		t.Error(err)
+	}
+}